@@ -0,0 +1,185 @@
+package infinity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"golang.org/x/oauth2"
+
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+// GetAzureADCredential returns the azure ad (entra id) token credential for the given settings.
+// Token requests are sent using the given http client so that the proxy and TLS settings of the datasource are respected.
+func GetAzureADCredential(settings models.InfinitySettings, httpClient *http.Client) (azcore.TokenCredential, error) {
+	azSettings := settings.AzureADSettings
+	clientOptions := azcore.ClientOptions{Cloud: getAzureCloudConfiguration(azSettings)}
+	if httpClient != nil {
+		clientOptions.Transport = httpClient
+	}
+	disableInstanceDiscovery := strings.TrimSpace(azSettings.AuthorityHost) != ""
+	switch azSettings.AuthType {
+	case models.AzureADAuthTypeClientCertificate:
+		certs, key, err := azidentity.ParseCertificates([]byte(strings.ReplaceAll(azSettings.ClientCertificate, "\\n", "\n")), []byte(azSettings.ClientCertificatePassword))
+		if err != nil {
+			return nil, fmt.Errorf("invalid azure ad client certificate. %w", err)
+		}
+		return azidentity.NewClientCertificateCredential(azSettings.TenantID, azSettings.ClientID, certs, key, &azidentity.ClientCertificateCredentialOptions{
+			ClientOptions:            clientOptions,
+			DisableInstanceDiscovery: disableInstanceDiscovery,
+		})
+	default:
+		return azidentity.NewClientSecretCredential(azSettings.TenantID, azSettings.ClientID, azSettings.ClientSecret, &azidentity.ClientSecretCredentialOptions{
+			ClientOptions:            clientOptions,
+			DisableInstanceDiscovery: disableInstanceDiscovery,
+		})
+	}
+}
+
+func getAzureCloudConfiguration(azSettings models.AzureADSettings) cloud.Configuration {
+	if authorityHost := strings.TrimSpace(azSettings.AuthorityHost); authorityHost != "" {
+		return cloud.Configuration{ActiveDirectoryAuthorityHost: authorityHost, Services: map[cloud.ServiceName]cloud.ServiceConfiguration{}}
+	}
+	switch azSettings.Cloud {
+	case models.AzureADCloudChina:
+		return cloud.AzureChina
+	case models.AzureADCloudGovernment:
+		return cloud.AzureGovernment
+	default:
+		return cloud.AzurePublic
+	}
+}
+
+// azureADTransport adds the azure ad token to the requests. Token is fetched using the request context so that the query cancellation and timeouts
+// are respected, and reused until it expires
+type azureADTransport struct {
+	base       http.RoundTripper
+	credential azcore.TokenCredential
+	scopes     []string
+	mu         sync.Mutex
+	token      *oauth2.Token
+}
+
+func (t *azureADTransport) getToken(ctx context.Context) (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token.Valid() {
+		return t.token, nil
+	}
+	token, err := t.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: t.scopes})
+	if err != nil {
+		return nil, fmt.Errorf("error getting azure ad token. %w", err)
+	}
+	t.token = &oauth2.Token{AccessToken: token.Token, TokenType: "Bearer", Expiry: token.ExpiresOn}
+	return t.token, nil
+}
+
+func (t *azureADTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.getToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req2 := req.Clone(req.Context())
+	token.SetAuthHeader(req2)
+	return t.base.RoundTrip(req2)
+}
+
+func ApplyAzureADAuth(ctx context.Context, httpClient *http.Client, settings models.InfinitySettings) (*http.Client, error) {
+	_, span := tracing.DefaultTracer().Start(ctx, "ApplyAzureADAuth")
+	defer span.End()
+	if settings.AuthenticationMethod != models.AuthenticationMethodAzureAD {
+		return httpClient, nil
+	}
+	scopes := []string{}
+	for _, scope := range settings.AzureADSettings.Scopes {
+		if strings.TrimSpace(scope) != "" {
+			scopes = append(scopes, strings.TrimSpace(scope))
+		}
+	}
+	if len(scopes) < 1 {
+		// azure blob datasource uses the azure ad credential directly and doesn't need the scopes
+		if settings.AzureBlobAccountName != "" && len(settings.AllowedHosts) < 1 {
+			return httpClient, nil
+		}
+		err := errors.New("invalid or empty azure ad scopes")
+		span.RecordError(err)
+		return nil, err
+	}
+	credential, err := GetAzureADCredential(settings, httpClient)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &http.Client{
+		Transport: &azureADTransport{base: base, credential: credential, scopes: scopes},
+		Timeout:   httpClient.Timeout,
+	}, nil
+}
+
+func getAzureBlobClientURL(settings models.InfinitySettings) string {
+	clientUrl := "https://%s.blob.core.windows.net/"
+	if settings.AzureBlobAccountUrl != "" {
+		clientUrl = settings.AzureBlobAccountUrl
+	}
+	if strings.Contains(clientUrl, "%s") {
+		clientUrl = fmt.Sprintf(clientUrl, settings.AzureBlobAccountName)
+	}
+	return clientUrl
+}
+
+// getAzureBlobClientOptions returns the blob client options using the given http client so that the proxy and TLS settings of the datasource are respected
+func getAzureBlobClientOptions(httpClient *http.Client) *azblob.ClientOptions {
+	if httpClient == nil {
+		return nil
+	}
+	return &azblob.ClientOptions{ClientOptions: azcore.ClientOptions{Transport: httpClient}}
+}
+
+func getAzureBlobClient(ctx context.Context, settings models.InfinitySettings, httpClient *http.Client) (*azblob.Client, error) {
+	_, span := tracing.DefaultTracer().Start(ctx, "getAzureBlobClient")
+	defer span.End()
+	var azClient *azblob.Client
+	switch settings.AuthenticationMethod {
+	case models.AuthenticationMethodAzureAD:
+		credential, err := GetAzureADCredential(settings, httpClient)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("invalid azure ad credentials. %s", err)
+		}
+		azClient, err = azblob.NewClient(getAzureBlobClientURL(settings), credential, getAzureBlobClientOptions(httpClient))
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("invalid azure blob client. %s", err)
+		}
+	default:
+		cred, err := azblob.NewSharedKeyCredential(settings.AzureBlobAccountName, settings.AzureBlobAccountKey)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("invalid azure blob credentials. %s", err)
+		}
+		azClient, err = azblob.NewClientWithSharedKeyCredential(getAzureBlobClientURL(settings), cred, getAzureBlobClientOptions(httpClient))
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("invalid azure blob client. %s", err)
+		}
+	}
+	if azClient == nil {
+		span.RecordError(errors.New("invalid/empty azure blob client"))
+		return nil, errors.New("invalid/empty azure blob client")
+	}
+	return azClient, nil
+}
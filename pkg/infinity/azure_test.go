@@ -0,0 +1,118 @@
+package infinity_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestAzureADAuthentication(t *testing.T) {
+	t.Run("should acquire and reuse the azure ad token", func(t *testing.T) {
+		tokenRequests := 0
+		server := newTestTLSServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/my-tenant/v2.0/.well-known/openid-configuration":
+				host := "https://" + r.Host
+				_, _ = io.WriteString(w, fmt.Sprintf(`{"token_endpoint":"%s/my-tenant/oauth2/v2.0/token","authorization_endpoint":"%s/my-tenant/oauth2/v2.0/authorize","issuer":"%s/my-tenant/v2.0"}`, host, host, host))
+			case "/my-tenant/oauth2/v2.0/token":
+				tokenRequests++
+				require.Nil(t, r.ParseForm())
+				assert.Equal(t, "MY_CLIENT_ID", r.PostForm.Get("client_id"))
+				assert.Equal(t, "MY_CLIENT_SECRET", r.PostForm.Get("client_secret"))
+				assert.Contains(t, r.PostForm.Get("scope"), "api://my-api/.default")
+				_, _ = io.WriteString(w, `{"token_type":"Bearer","access_token":"aad-token","expires_in":3600}`)
+			default:
+				if r.Header.Get("Authorization") != "Bearer aad-token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = io.WriteString(w, `{"foo":"bar"}`)
+			}
+		})
+		client := newTestClient(t, models.InfinitySettings{
+			URL:                  server.URL,
+			AllowedHosts:         []string{server.URL},
+			InsecureSkipVerify:   true,
+			AuthenticationMethod: models.AuthenticationMethodAzureAD,
+			AzureADSettings: models.AzureADSettings{
+				AuthType:      models.AzureADAuthTypeClientSecret,
+				AuthorityHost: server.URL,
+				TenantID:      "my-tenant",
+				ClientID:      "MY_CLIENT_ID",
+				ClientSecret:  "MY_CLIENT_SECRET",
+				Scopes:        []string{"api://my-api/.default"},
+			},
+		})
+		for i := 0; i < 2; i++ {
+			res := queryData(context.Background(), backend.DataQuery{
+				JSON: []byte(fmt.Sprintf(`{
+					"type": "json",
+					"source": "url",
+					"url":  "%s/something-else"
+				}`, server.URL)),
+			}, *client, map[string]string{})
+			require.NotNil(t, res)
+			require.Nil(t, res.Error)
+			metaData := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+			require.NotNil(t, metaData)
+			require.Equal(t, map[string]any(map[string]any{"foo": "bar"}), metaData.Data)
+		}
+		require.Equal(t, 1, tokenRequests)
+	})
+	t.Run("should not fetch the token beyond the query deadline", func(t *testing.T) {
+		release := make(chan struct{})
+		server := newTestTLSServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/my-tenant/v2.0/.well-known/openid-configuration":
+				host := "https://" + r.Host
+				_, _ = io.WriteString(w, fmt.Sprintf(`{"token_endpoint":"%s/my-tenant/oauth2/v2.0/token","authorization_endpoint":"%s/my-tenant/oauth2/v2.0/authorize","issuer":"%s/my-tenant/v2.0"}`, host, host, host))
+			case "/my-tenant/oauth2/v2.0/token":
+				<-release
+			}
+		})
+		client := newTestClient(t, models.InfinitySettings{
+			URL:                  server.URL,
+			AllowedHosts:         []string{server.URL},
+			InsecureSkipVerify:   true,
+			AuthenticationMethod: models.AuthenticationMethodAzureAD,
+			AzureADSettings: models.AzureADSettings{
+				AuthType:      models.AzureADAuthTypeClientSecret,
+				AuthorityHost: server.URL,
+				TenantID:      "my-tenant",
+				ClientID:      "MY_CLIENT_ID",
+				ClientSecret:  "MY_CLIENT_SECRET",
+				Scopes:        []string{"api://my-api/.default"},
+			},
+		})
+		// runs before the server is closed
+		t.Cleanup(func() { close(release) })
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		startTime := time.Now()
+		res := queryData(ctx, backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "url": "%s/foo" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		assert.Less(t, time.Since(startTime), 2*time.Second)
+	})
+	t.Run("should error when the scopes are empty", func(t *testing.T) {
+		_, err := infinity.NewClient(context.TODO(), models.InfinitySettings{
+			AllowedHosts:         []string{"https://foo.com"},
+			AuthenticationMethod: models.AuthenticationMethodAzureAD,
+			AzureADSettings:      models.AzureADSettings{TenantID: "my-tenant", ClientID: "MY_CLIENT_ID", ClientSecret: "MY_CLIENT_SECRET"},
+		})
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "invalid or empty azure ad scopes")
+	})
+}
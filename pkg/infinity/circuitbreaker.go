@@ -0,0 +1,125 @@
+package infinity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+const (
+	defaultCircuitBreakerFailureThreshold = 5
+	defaultCircuitBreakerOpenDuration     = 30 * time.Second
+)
+
+type circuitResult int
+
+const (
+	circuitResultSuccess circuitResult = iota
+	circuitResultFailure
+	// circuitResultIgnored is the result of the requests cancelled by the caller, which are not counted as failures
+	circuitResultIgnored
+)
+
+// circuitBreaker tracks the consecutive failures of each host. The circuit of the host opens after the failure threshold and
+// the requests fail fast until the open duration is elapsed. Then the circuit is half-open and a single probe request decides
+// whether the circuit closes again or stays open for another open duration
+type circuitBreaker struct {
+	mu           sync.Mutex
+	threshold    int
+	openDuration time.Duration
+	hosts        map[string]*circuitState
+}
+
+type circuitState struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func getCircuitBreaker(settings models.CircuitBreakerSettings) *circuitBreaker {
+	if !settings.Enabled {
+		return nil
+	}
+	return &circuitBreaker{
+		threshold:    withDefault(settings.FailureThreshold, defaultCircuitBreakerFailureThreshold),
+		openDuration: withDefaultDuration(settings.OpenDurationInSeconds, defaultCircuitBreakerOpenDuration),
+		hosts:        map[string]*circuitState{},
+	}
+}
+
+// allow returns an error when the circuit of the host is open. Every allowed request must be followed by the done call with its result
+func (cb *circuitBreaker) allow(host string, now time.Time) error {
+	if cb == nil {
+		return nil
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	state, ok := cb.hosts[host]
+	if !ok || state.failures < cb.threshold {
+		return nil
+	}
+	if retryIn := state.openedAt.Add(cb.openDuration).Sub(now); retryIn > 0 {
+		return fmt.Errorf("circuit breaker is open for %s after %d consecutive failures. retrying in %s", host, state.failures, retryIn.Round(time.Second))
+	}
+	if state.probing {
+		return fmt.Errorf("circuit breaker is half-open for %s. waiting for the probe request", host)
+	}
+	state.probing = true
+	return nil
+}
+
+func (cb *circuitBreaker) done(host string, result circuitResult, now time.Time) {
+	if cb == nil {
+		return
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	state, ok := cb.hosts[host]
+	switch result {
+	case circuitResultSuccess:
+		delete(cb.hosts, host)
+	case circuitResultFailure:
+		if !ok {
+			state = &circuitState{}
+			cb.hosts[host] = state
+		}
+		state.failures++
+		state.probing = false
+		if state.failures >= cb.threshold {
+			state.openedAt = now
+		}
+	default:
+		if ok {
+			state.probing = false
+		}
+	}
+}
+
+// getCircuitHost returns the scheme and host of the url, which is the key of the circuit
+func getCircuitHost(requestURL string) string {
+	if socketPath, _, ok := splitUnixSocketURL(requestURL); ok {
+		return unixSocketURLPrefix + socketPath
+	}
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return requestURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// getCircuitResult returns the failure for the transport errors and the server errors. Client errors such as 404 and the responses
+// which can't be parsed are not failures of the upstream
+func getCircuitResult(ctx context.Context, statusCode int, err error) circuitResult {
+	if ctx.Err() != nil {
+		return circuitResultIgnored
+	}
+	if err != nil && (statusCode == 0 || statusCode >= http.StatusInternalServerError) {
+		return circuitResultFailure
+	}
+	return circuitResultSuccess
+}
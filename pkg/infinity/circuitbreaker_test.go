@@ -0,0 +1,66 @@
+package infinity_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	failing, hits := true, 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		hits++
+		if failing {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = io.WriteString(w, `[{"id":1}]`)
+	})
+	getHits := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits
+	}
+	client := newTestClient(t, models.InfinitySettings{
+		CircuitBreaker: models.CircuitBreakerSettings{Enabled: true, FailureThreshold: 2, OpenDurationInSeconds: 1},
+	})
+	query := func() backend.DataResponse {
+		return queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/foo" }`, server.URL)),
+		}, *client, map[string]string{})
+	}
+	t.Run("should open the circuit after the consecutive failures and fail fast", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			require.NotNil(t, query().Error)
+		}
+		require.Equal(t, 2, getHits())
+		res := query()
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "circuit breaker is open")
+		require.Equal(t, 2, getHits())
+	})
+	t.Run("should close the circuit when the probe request succeeds", func(t *testing.T) {
+		mu.Lock()
+		failing = false
+		mu.Unlock()
+		time.Sleep(1100 * time.Millisecond)
+		for i := 0; i < 2; i++ {
+			res := query()
+			require.Nil(t, res.Error)
+			require.Equal(t, 1, res.Frames[0].Rows())
+		}
+		require.Equal(t, 4, getHits())
+	})
+}
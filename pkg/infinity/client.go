@@ -26,7 +26,26 @@ type Client struct {
 	Settings        models.InfinitySettings
 	HttpClient      *http.Client
 	AzureBlobClient *azblob.Client
+	MercuryPool     *mercury.Pool
+	HostProfiles    []HostProfileClient
 	IsMock          bool
+	transport       *http.Transport
+	clientState
+}
+
+// clientState is the state of the datasource instance which outlives a single client, such as the rate limits, the circuits and
+// the last successful responses. It only holds references, so that it is shared when handed over to the new client of the instance
+type clientState struct {
+	rateLimiters   []*rateLimiter
+	circuitBreaker *circuitBreaker
+	staleFrames    *staleFrames
+}
+
+// HostProfileClient is the client used for the requests to the urls starting with the URLPrefix of the host profile
+type HostProfileClient struct {
+	Name      string
+	URLPrefix string
+	Client    *Client
 }
 
 func GetTLSConfigFromSettings(settings models.InfinitySettings) (*tls.Config, error) {
@@ -35,10 +54,7 @@ func GetTLSConfigFromSettings(settings models.InfinitySettings) (*tls.Config, er
 		ServerName:         settings.ServerName,
 	}
 	if settings.TLSClientAuth {
-		if settings.TLSClientCert == "" || settings.TLSClientKey == "" {
-			return nil, errors.New("invalid Client cert or key")
-		}
-		cert, err := tls.X509KeyPair([]byte(settings.TLSClientCert), []byte(settings.TLSClientKey))
+		cert, err := getTLSClientCertificate(settings)
 		if err != nil {
 			return nil, err
 		}
@@ -52,29 +68,36 @@ func GetTLSConfigFromSettings(settings models.InfinitySettings) (*tls.Config, er
 		}
 		tlsConfig.RootCAs = caPool
 	}
+	minVersion, err := models.GetTLSMinVersion(settings.TLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.MinVersion = minVersion
+	cipherSuites, err := models.GetTLSCipherSuites(settings.TLSCipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.CipherSuites = cipherSuites
+	pinnedPublicKeys, err := models.GetTLSPinnedPublicKeys(settings.TLSPinnedPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	pinnedHosts, err := models.GetTLSPinnedHosts(settings.TLSPinnedHosts, settings.TLSPinnedPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(pinnedPublicKeys) > 0 {
+		tlsConfig.VerifyConnection = verifyPinnedPublicKeys(pinnedHosts, pinnedPublicKeys)
+	}
 	return tlsConfig, nil
 }
 
 func getBaseHTTPClient(ctx context.Context, settings models.InfinitySettings) *http.Client {
-	tlsConfig, err := GetTLSConfigFromSettings(settings)
+	transport, err := getTransport(settings)
 	if err != nil {
+		backend.Logger.Error("error configuring the http transport", "err", err.Error(), "proxy_url", settings.ProxyUrl)
 		return nil
 	}
-	transport := &http.Transport{TLSClientConfig: tlsConfig}
-	switch settings.ProxyType {
-	case models.ProxyTypeNone:
-		backend.Logger.Debug("proxy type is set to none. Not using the proxy")
-	case models.ProxyTypeUrl:
-		backend.Logger.Debug("proxy type is set to url. Using the proxy", "proxy_url", settings.ProxyUrl)
-		u, err := url.Parse(settings.ProxyUrl)
-		if err != nil {
-			backend.Logger.Error("error parsing proxy url", "err", err.Error(), "proxy_url", settings.ProxyUrl)
-			return nil
-		}
-		transport.Proxy = http.ProxyURL(u)
-	default:
-		transport.Proxy = http.ProxyFromEnvironment
-	}
 	return &http.Client{
 		Transport: transport,
 		Timeout:   time.Second * time.Duration(settings.TimeoutInSeconds),
@@ -98,40 +121,70 @@ func NewClient(ctx context.Context, settings models.InfinitySettings) (client *C
 		span.RecordError(errors.New("invalid http client"))
 		return nil, errors.New("invalid http client")
 	}
+	transport, _ := httpClient.Transport.(*http.Transport)
 	httpClient = ApplyDigestAuth(ctx, httpClient, settings)
 	httpClient = ApplyOAuthClientCredentials(ctx, httpClient, settings)
 	httpClient = ApplyOAuthJWT(ctx, httpClient, settings)
 	httpClient = ApplyAWSAuth(ctx, httpClient, settings)
+	baseHttpClient := httpClient
+	httpClient, err = ApplyAzureADAuth(ctx, httpClient, settings)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(500, err.Error())
+		return nil, fmt.Errorf("invalid azure ad credentials. %s", err)
+	}
+	httpClient, err = ApplyZCapInvocation(ctx, httpClient, settings)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(500, err.Error())
+		return nil, fmt.Errorf("invalid zcap credentials. %s", err)
+	}
+	if settings.AuthenticationMethod == models.AuthenticationMethodZCAP && !isNativeZCap(settings) {
+		// the adapter binary is executed by the grafana server. so only the adapters allowed by the plugin config can be used
+		if err := models.ValidateZCapAdapter(settings.ZCapSettings, models.GetZCapAdapterAllowedPaths()); err != nil {
+			span.RecordError(err)
+			span.SetStatus(500, err.Error())
+			return nil, fmt.Errorf("invalid zcap adapter. %w", err)
+		}
+	}
+	httpClient.CheckRedirect = getCheckRedirect(settings)
 	client = &Client{
 		Settings:   settings,
 		HttpClient: httpClient,
+		transport:  transport,
+		clientState: clientState{
+			rateLimiters:   getRateLimiters(settings.RateLimits),
+			circuitBreaker: getCircuitBreaker(settings.CircuitBreaker),
+		},
+	}
+	if settings.ServeStaleOnError && !settings.ForwardOauthIdentity {
+		// the responses of the forwarded user identities must never be served to the other users
+		client.staleFrames = newStaleFrames()
 	}
-	if settings.AuthenticationMethod == models.AuthenticationMethodAzureBlob {
-		cred, err := azblob.NewSharedKeyCredential(settings.AzureBlobAccountName, settings.AzureBlobAccountKey)
+	if settings.AuthenticationMethod == models.AuthenticationMethodAzureBlob || (settings.AuthenticationMethod == models.AuthenticationMethodAzureAD && settings.AzureBlobAccountName != "") {
+		azClient, err := getAzureBlobClient(ctx, settings, baseHttpClient)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(500, err.Error())
-			return nil, fmt.Errorf("invalid azure blob credentials. %s", err)
-		}
-		clientUrl := "https://%s.blob.core.windows.net/"
-		if settings.AzureBlobAccountUrl != "" {
-			clientUrl = settings.AzureBlobAccountUrl
-		}
-		if strings.Contains(clientUrl, "%s") {
-			clientUrl = fmt.Sprintf(clientUrl, settings.AzureBlobAccountName)
+			return nil, err
 		}
-		azClient, err := azblob.NewClientWithSharedKeyCredential(clientUrl, cred, nil)
+		client.AzureBlobClient = azClient
+	}
+	for _, profile := range settings.HostProfiles {
+		profileClient, err := NewClient(ctx, profile.Settings)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(500, err.Error())
-			return nil, fmt.Errorf("invalid azure blob client. %s", err)
-		}
-		if azClient == nil {
-			span.RecordError(errors.New("invalid/empty azure blob client"))
-			span.SetStatus(500, "invalid/empty azure blob client")
-			return nil, errors.New("invalid/empty azure blob client")
+			client.Dispose()
+			return nil, fmt.Errorf("invalid host profile %s. %w", profile.Name, err)
 		}
-		client.AzureBlobClient = azClient
+		// rate limits and circuits are shared with the host profiles so that they apply across all the requests of the instance
+		profileClient.rateLimiters = client.rateLimiters
+		profileClient.circuitBreaker = client.circuitBreaker
+		client.HostProfiles = append(client.HostProfiles, HostProfileClient{Name: profile.Name, URLPrefix: profile.URLPrefix, Client: profileClient})
+	}
+	if settings.AuthenticationMethod == models.AuthenticationMethodZCAP && !isNativeZCap(settings) && settings.ZCapSettings.PersistentAdapter {
+		client.MercuryPool = getMercuryPool(settings)
 	}
 	if settings.IsMock {
 		client.IsMock = true
@@ -139,6 +192,59 @@ func NewClient(ctx context.Context, settings models.InfinitySettings) (client *C
 	return client, err
 }
 
+// ShareStateOf makes the client continue with the rate limits, circuits and stale responses of the previous client of the
+// same datasource instance instead of starting afresh, e.g. when the client is replaced after the secret references are refreshed
+func (client *Client) ShareStateOf(previous *Client) {
+	if previous == nil {
+		return
+	}
+	staleFrames := client.staleFrames
+	client.clientState = previous.clientState
+	if staleFrames == nil || client.staleFrames == nil {
+		client.staleFrames = staleFrames
+	}
+	for _, profile := range client.HostProfiles {
+		profile.Client.rateLimiters = client.rateLimiters
+		profile.Client.circuitBreaker = client.circuitBreaker
+		for _, previousProfile := range previous.HostProfiles {
+			if previousProfile.Name == profile.Name && previousProfile.Client != nil && profile.Client.staleFrames != nil && previousProfile.Client.staleFrames != nil {
+				profile.Client.staleFrames = previousProfile.Client.staleFrames
+			}
+		}
+	}
+}
+
+// Dispose releases the resources held by the client such as the idle connections of the transport and the persistent mercury client adapter processes
+func (client *Client) Dispose() {
+	if client.transport != nil {
+		client.transport.CloseIdleConnections()
+	}
+	if client.MercuryPool != nil {
+		_ = client.MercuryPool.Close()
+	}
+	for _, profile := range client.HostProfiles {
+		profile.Client.Dispose()
+	}
+}
+
+// getClientForQuery returns the client of the first host profile matching the query url, or the client itself when none of the profiles match
+func (client *Client) getClientForQuery(ctx context.Context, query models.Query) *Client {
+	if len(client.HostProfiles) == 0 {
+		return client
+	}
+	url, err := GetQueryURL(ctx, client.Settings, query, false)
+	if err != nil {
+		return client
+	}
+	for _, profile := range client.HostProfiles {
+		if matchURLPrefix(url, profile.URLPrefix) {
+			backend.Logger.Debug("using host profile for the request", "profile", profile.Name, "url_prefix", profile.URLPrefix)
+			return profile.Client
+		}
+	}
+	return client
+}
+
 func replaceSect(input string, settings models.InfinitySettings, includeSect bool) string {
 	for key, value := range settings.SecureQueryFields {
 		if includeSect {
@@ -151,36 +257,6 @@ func replaceSect(input string, settings models.InfinitySettings, includeSect boo
 	return input
 }
 
-var includeSect bool = true
-
-func ApplyZCapAuth(settings models.InfinitySettings) (string, string, error) {
-	var contentT []byte
-	var dataT []byte
-	var err error
-
-	zcapInputTarget := dummyHeader
-
-	if includeSect {
-		zcapInputTarget = settings.ZCapJsonPath
-
-	}
-	var operation string = "download"
-	var target string = zcapInputTarget
-
-	content, data, err := mercury.Request(operation, target)
-	//download - data
-	//request - content
-
-	if err != nil {
-		backend.Logger.Error("Error:", err)
-	}
-	contentT = content
-	dataT = data
-
-	return string(contentT), string(dataT), err
-
-}
-
 func (client *Client) req(ctx context.Context, url string, body io.Reader, settings models.InfinitySettings, query models.Query, requestHeaders map[string]string) (obj any, statusCode int, duration time.Duration, err error) {
 	ctx, span := tracing.DefaultTracer().Start(ctx, "client.req")
 	defer span.End()
@@ -191,21 +267,34 @@ func (client *Client) req(ctx context.Context, url string, body io.Reader, setti
 		backend.Logger.Error("url is not in the allowed list. make sure to match the base URL with the settings", "url", req.URL.String())
 		return nil, http.StatusUnauthorized, 0, errors.New("requested URL is not allowed. To allow this URL, update the datasource config Security -> Allowed Hosts section")
 	}
+	if socketPath, ok := getUnixSocketPath(req.URL.Host); ok {
+		req = req.WithContext(withAllowedUnixSocket(req.Context(), socketPath))
+	}
+	rateLimitURL := req.URL.String()
+	if socketURL, ok := getUnixSocketURL(rateLimitURL); ok {
+		rateLimitURL = socketURL
+	}
+	circuitHost := getCircuitHost(rateLimitURL)
+	if err := client.circuitBreaker.allow(circuitHost, time.Now()); err != nil {
+		backend.Logger.Warn("circuit breaker is open", "url", url, "error", err.Error())
+		return nil, http.StatusServiceUnavailable, 0, err
+	}
+	release, err := client.acquireRateLimit(ctx, rateLimitURL)
+	if err != nil {
+		client.circuitBreaker.done(circuitHost, circuitResultIgnored, time.Now())
+		backend.Logger.Error("error waiting for the rate limit", "url", url, "error", err.Error())
+		return nil, http.StatusTooManyRequests, time.Since(startTime), err
+	}
+	defer release()
+	defer func() {
+		client.circuitBreaker.done(circuitHost, getCircuitResult(ctx, statusCode, err), time.Now())
+	}()
+	if settings.AuthenticationMethod == models.AuthenticationMethodZCAP && !isNativeZCap(settings) {
+		return client.reqWithMercury(ctx, url, req, query)
+	}
 	backend.Logger.Debug("yesoreyeram-infinity-datasource plugin is now requesting URL", "url", req.URL.String())
 	res, err := client.HttpClient.Do(req)
 	duration = time.Since(startTime)
-
-	// Use MercuryClient for zCap Authenticated Requests
-	if settings.AuthenticationMethod == models.AuthenticationMethodZCAP {
-		console, data, err := ApplyZCapAuth(settings)
-
-		backend.Logger.Info("entered in ZCAP", console) //displays in powershell log when running
-		res.StatusCode = 200
-		//duration = time.Since(startTime)
-
-		return data, res.StatusCode, duration, err
-	}
-
 	if res != nil {
 		defer res.Body.Close()
 	}
@@ -224,21 +313,27 @@ func (client *Client) req(ctx context.Context, url string, body io.Reader, setti
 	if res.StatusCode >= http.StatusBadRequest {
 		return nil, res.StatusCode, duration, errors.New(res.Status)
 	}
+	setResponseHeaders(ctx, res.Header)
 	bodyBytes, err := io.ReadAll(res.Body)
 	if err != nil {
 		backend.Logger.Error("error reading response body", "url", url, "error", err.Error())
 		return nil, res.StatusCode, duration, err
 	}
+	out, err := parseResponseBody(bodyBytes, query, res.Header, url)
+	return out, res.StatusCode, duration, err
+}
+
+func parseResponseBody(bodyBytes []byte, query models.Query, responseHeaders http.Header, url string) (any, error) {
 	bodyBytes = removeBOMContent(bodyBytes)
-	if CanParseAsJSON(query.Type, res.Header) {
+	if CanParseAsJSON(query.Type, responseHeaders) {
 		var out any
 		err := json.Unmarshal(bodyBytes, &out)
 		if err != nil {
 			backend.Logger.Error("error un-marshaling JSON response", "url", url, "error", err.Error())
 		}
-		return out, res.StatusCode, duration, err
+		return out, err
 	}
-	return string(bodyBytes), res.StatusCode, duration, err
+	return string(bodyBytes), nil
 }
 
 // https://stackoverflow.com/questions/31398044/got-error-invalid-character-%C3%AF-looking-for-beginning-of-value-from-json-unmar
@@ -274,6 +369,7 @@ func (client *Client) GetResults(ctx context.Context, query models.Query, reques
 		}
 		return string(bodyBytes), http.StatusOK, 0, nil
 	}
+	client = client.getClientForQuery(ctx, query)
 	switch strings.ToUpper(query.URLOptions.Method) {
 	case http.MethodPost:
 		body := GetQueryBody(query)
@@ -296,8 +392,59 @@ func CanParseAsJSON(queryType models.QueryType, responseHeaders http.Header) boo
 	return false
 }
 
+// matchURLPrefix returns true when the url has the same scheme, host and port as the prefix and its path is
+// either the path of the prefix or any path below it. `https://foo.com/api` doesn't match `https://foo.com/apis` or `https://foo.com.evil.com/api`
+func matchURLPrefix(rawURL string, prefix string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	p, err := url.Parse(strings.TrimSpace(prefix))
+	if err != nil || p.Host == "" {
+		return false
+	}
+	if !strings.EqualFold(u.Scheme, p.Scheme) || !strings.EqualFold(u.Hostname(), p.Hostname()) || getURLPort(u) != getURLPort(p) {
+		return false
+	}
+	prefixPath := strings.TrimSuffix(p.EscapedPath(), "/")
+	urlPath := u.EscapedPath()
+	return prefixPath == "" || urlPath == prefixPath || strings.HasPrefix(urlPath, prefixPath+"/")
+}
+
+func getURLPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
+
+// getCheckRedirect re-runs the allowed hosts check for the redirects and never follows the redirects to the unix sockets
+func getCheckRedirect(settings models.InfinitySettings) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if isUnixSocketRequest(req) {
+			return errors.New("redirects to the unix sockets are not allowed")
+		}
+		if !CanAllowURL(req.URL.String(), settings.AllowedHosts) {
+			return fmt.Errorf("redirect to %s is not allowed. To allow this URL, update the datasource config Security -> Allowed Hosts section", req.URL.Redacted())
+		}
+		return nil
+	}
+}
+
 func CanAllowURL(url string, allowedHosts []string) bool {
 	allow := false
+	if socketURL, ok := getUnixSocketURL(url); ok {
+		return canAllowUnixSocketURL(socketURL, allowedHosts)
+	}
 	if len(allowedHosts) == 0 {
 		return true
 	}
@@ -5,10 +5,14 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
 	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
 )
@@ -111,6 +115,35 @@ func TestCanAllowURL(t *testing.T) {
 			allowedHosts: []string{"https://foo.com/", "https://bar.com/", "https://baz.com/"},
 			want:         true,
 		},
+		{
+			name: "should not allow unix sockets by default",
+			url:  "unix:///var/run/docker.sock:/containers/json",
+			want: false,
+		},
+		{
+			name:         "should allow unix sockets in the allowed hosts",
+			url:          "unix:///var/run/docker.sock:/containers/json",
+			allowedHosts: []string{"https://foo.com/", "unix:///var/run/docker.sock"},
+			want:         true,
+		},
+		{
+			name:         "should match the unix socket path exactly",
+			url:          "unix:///var/run/docker.sock.bak:/containers/json",
+			allowedHosts: []string{"unix:///var/run/docker.sock"},
+			want:         false,
+		},
+		{
+			name:         "should match the request path of the unix socket",
+			url:          "unix:///var/run/docker.sock:/images/json",
+			allowedHosts: []string{"unix:///var/run/docker.sock:/containers"},
+			want:         false,
+		},
+		{
+			name:         "should allow the unix socket request urls",
+			url:          "http://2f7661722f72756e2f646f636b65722e736f636b.unix/containers/json",
+			allowedHosts: []string{"unix:///var/run/docker.sock:/containers"},
+			want:         true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -284,3 +317,96 @@ JIoTknAYsX8acOy3XzTdA+mN139mLnG+Tpu1bbjcJLihtPieo5NVWBi/jZedo0Ex
 l7aV0Ij7+2S+ynhQUspKZ+fu3Ng+UuMauX9RpkMsfxRyKuj4WrOMVfI=
 -----END RSA PRIVATE KEY-----`
 )
+
+func TestClient_ShareStateOf(t *testing.T) {
+	hits := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	settings := models.InfinitySettings{
+		CircuitBreaker: models.CircuitBreakerSettings{Enabled: true, FailureThreshold: 2, OpenDurationInSeconds: 60},
+	}
+	query := backend.DataQuery{JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/foo" }`, server.URL))}
+	previous := newTestClient(t, settings)
+	for i := 0; i < 2; i++ {
+		require.NotNil(t, queryData(context.Background(), query, *previous, map[string]string{}).Error)
+	}
+	client := newTestClient(t, settings)
+	client.ShareStateOf(previous)
+	res := queryData(context.Background(), query, *client, map[string]string{})
+	require.NotNil(t, res.Error)
+	assert.Contains(t, res.Error.Error(), "circuit breaker is open")
+	require.Equal(t, 2, hits)
+}
+
+func TestHostProfiles(t *testing.T) {
+	serverA := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token-a" || r.Header.Get("X-Base") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"server":"a"}`)
+	})
+	serverB := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != "token-b" || r.Header.Get("X-Tenant") != "tenant-b" || r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"server":"b"}`)
+	})
+	serverBase := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			if r.Header.Get("Authorization") != "Bearer token-c" || r.Header.Get("X-Base") != "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = io.WriteString(w, `{"server":"c"}`)
+			return
+		}
+		if r.Header.Get("X-Base") != "base" || r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, `{"server":"base"}`)
+	})
+	settings, err := models.LoadSettings(backend.DataSourceInstanceSettings{
+		JSONData: []byte(fmt.Sprintf(`{
+			"allowedHosts": ["%s", "%s", "%s"],
+			"httpHeaderName1": "X-Base",
+			"hostProfiles": [
+				{ "name": "a", "urlPrefix": "%s/", "auth_method": "bearerToken" },
+				{ "name": "b", "urlPrefix": "%s/", "auth_method": "apiKey", "apiKeyKey": "key", "apiKeyType": "query", "httpHeaderName1": "X-Tenant" },
+				{ "name": "c", "urlPrefix": "%s/api", "auth_method": "bearerToken" }
+			]
+		}`, serverA.URL, serverB.URL, serverBase.URL, serverA.URL, serverB.URL, serverBase.URL)),
+		DecryptedSecureJSONData: map[string]string{
+			"httpHeaderValue1":              "base",
+			"hostProfile1.bearerToken":      "token-a",
+			"hostProfile2.apiKeyValue":      "token-b",
+			"hostProfile2.httpHeaderValue1": "tenant-b",
+			"hostProfile3.bearerToken":      "token-c",
+		},
+	})
+	require.Nil(t, err)
+	require.Nil(t, settings.Validate())
+	client := newTestClient(t, settings)
+	for _, tt := range []struct {
+		url  string
+		want string
+	}{
+		{url: serverA.URL + "/foo", want: "a"},
+		{url: serverB.URL + "/foo", want: "b"},
+		{url: serverBase.URL + "/foo", want: "base"},
+		{url: serverBase.URL + "/api/foo", want: "c"},
+		{url: serverBase.URL + "/apis", want: "base"},
+	} {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "url": "%s" }`, tt.url)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		metaData := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+		require.Equal(t, map[string]any{"server": tt.want}, metaData.Data)
+	}
+}
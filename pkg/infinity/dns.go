@@ -0,0 +1,68 @@
+package infinity
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+type dialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// getDialContext returns the dialer which connects to the unix sockets allowed for the request, the dns override address of the host or resolves the host using the custom dns server.
+// Only the dialed address is changed, so TLS SNI and certificate verification still use the original host name of the request
+func getDialContext(settings models.InfinitySettings) (dialContextFunc, error) {
+	dialer := getDialer(settings)
+	if settings.DNSServer != "" {
+		dnsServer, err := models.GetDNSServerAddress(settings.DNSServer)
+		if err != nil {
+			return nil, err
+		}
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{Timeout: dialer.Timeout}).DialContext(ctx, network, dnsServer)
+			},
+		}
+	}
+	overrides := settings.DNSOverrides
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if socketPath, ok := getUnixSocketPath(addr); ok {
+			if !isAllowedUnixSocket(ctx, socketPath) {
+				return nil, fmt.Errorf("unix socket %s is not allowed for this request", socketPath)
+			}
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+		if host, port, err := net.SplitHostPort(addr); err == nil {
+			if ip, ok := models.FindDNSOverride(overrides, host, port); ok {
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}, nil
+}
+
+// getCurlDNSArgs returns the curl --resolve and --dns-servers arguments equivalent to the dns settings for the given url
+func getCurlDNSArgs(settings models.InfinitySettings, u *url.URL) string {
+	args := []string{}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	if ip, ok := models.FindDNSOverride(settings.DNSOverrides, u.Hostname(), port); ok {
+		if strings.Contains(ip, ":") {
+			ip = "[" + ip + "]"
+		}
+		args = append(args, fmt.Sprintf("--resolve '%s:%s:%s'", u.Hostname(), port, ip))
+	}
+	if dnsServer, err := models.GetDNSServerAddress(settings.DNSServer); settings.DNSServer != "" && err == nil {
+		args = append(args, fmt.Sprintf("--dns-servers '%s'", dnsServer))
+	}
+	return strings.Join(args, " ")
+}
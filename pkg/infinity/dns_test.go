@@ -0,0 +1,88 @@
+package infinity_test
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSOverrides(t *testing.T) {
+	server := newTestTLSServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, fmt.Sprintf(`{"host":"%s"}`, r.Host))
+	})
+	serverURL, _ := url.Parse(server.URL)
+	t.Run("should connect to the override address and verify the certificate of the original host", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{
+			TLSAuthWithCACert: true,
+			TLSCACert:         string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+			DNSOverrides:      []models.DNSOverride{{Host: "example.com", Port: serverURL.Port(), IP: "127.0.0.1"}},
+		})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "url": "https://example.com:%s/foo" }`, serverURL.Port())),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		metaData := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+		require.Equal(t, map[string]any{"host": "example.com:" + serverURL.Port()}, metaData.Data)
+		require.Contains(t, res.Frames[0].Meta.ExecutedQueryString, fmt.Sprintf("--resolve 'example.com:%s:127.0.0.1'", serverURL.Port()))
+	})
+	t.Run("should resolve the host using the custom dns server", func(t *testing.T) {
+		dnsServer := startDNSServer(t, "127.0.0.1")
+		client := newTestClient(t, models.InfinitySettings{
+			InsecureSkipVerify: true,
+			DNSServer:          dnsServer,
+		})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "url": "https://api.split-horizon.test:%s/foo" }`, serverURL.Port())),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		metaData := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+		require.Equal(t, map[string]any{"host": "api.split-horizon.test:" + serverURL.Port()}, metaData.Data)
+		require.Contains(t, res.Frames[0].Meta.ExecutedQueryString, fmt.Sprintf("--dns-servers '%s'", dnsServer))
+	})
+}
+
+// startDNSServer starts a udp dns server which resolves all the A records to the given ip and returns the address of the server
+func startDNSServer(t *testing.T, ip string) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var msg dnsmessage.Message
+			if err := msg.Unpack(buf[:n]); err != nil || len(msg.Questions) == 0 {
+				continue
+			}
+			msg.Header.Response = true
+			msg.Header.Authoritative = true
+			if q := msg.Questions[0]; q.Type == dnsmessage.TypeA {
+				a := dnsmessage.AResource{}
+				copy(a.A[:], net.ParseIP(ip).To4())
+				msg.Answers = []dnsmessage.Resource{{Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60}, Body: &a}}
+			}
+			b, err := msg.Pack()
+			if err == nil {
+				_, _ = conn.WriteTo(b, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
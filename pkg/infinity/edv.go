@@ -0,0 +1,175 @@
+package infinity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/mercury"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+	"github.com/yesoreyeram/grafana-plugins/lib/go/jsonframer"
+)
+
+// GetFrameForEDVSources lists or fetches the documents of the encrypted data vault (EDV) using the mercury client adapter
+// and returns the decrypted document content as a frame along with the document id, sequence and meta columns
+func GetFrameForEDVSources(ctx context.Context, query models.Query, infClient Client) (*data.Frame, error) {
+	ctx, span := tracing.DefaultTracer().Start(ctx, "GetFrameForEDVSources")
+	defer span.End()
+	frame := GetDummyFrame(query)
+	if infClient.Settings.AuthenticationMethod != models.AuthenticationMethodZCAP || isNativeZCap(infClient.Settings) {
+		return frame, errors.New("edv queries require zcap authentication using the mercury client adapter")
+	}
+	vaultURL := strings.TrimSuffix(strings.TrimSpace(query.URL), "/")
+	if !CanAllowURL(vaultURL, infClient.Settings.AllowedHosts) {
+		backend.Logger.Error("url is not in the allowed list. make sure to match the base URL with the settings", "url", vaultURL)
+		return frame, errors.New("requested URL is not allowed. To allow this URL, update the datasource config Security -> Allowed Hosts section")
+	}
+	startTime := time.Now()
+	documents, statusCode, err := infClient.getEDVDocuments(ctx, vaultURL, query.EDVOptions)
+	customMeta := &CustomMeta{Query: query, Data: documents, ResponseCodeFromServer: statusCode, Duration: time.Since(startTime)}
+	if err != nil {
+		span.RecordError(err)
+		customMeta.Error = err.Error()
+		frame.Meta.Custom = customMeta
+		return frame, err
+	}
+	frame, err = getEDVFrame(ctx, frame, documents, query)
+	if frame == nil {
+		frame = GetDummyFrame(query)
+	}
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	if err != nil {
+		customMeta.Error = err.Error()
+	}
+	frame.Meta.Custom = customMeta
+	return frame, err
+}
+
+// getEDVDocuments fetches the documents by id when the document ids are specified, otherwise finds the documents matching the index filters
+func (client *Client) getEDVDocuments(ctx context.Context, vaultURL string, options *models.EDVOptions) ([]mercury.Document, int, error) {
+	if options == nil {
+		options = &models.EDVOptions{}
+	}
+	if len(options.DocumentIDs) == 0 {
+		res, err := client.mercuryRequest(ctx, mercury.OperationQuery, vaultURL, mercury.RequestOptions{Query: &mercury.DocumentQuery{
+			Index:  options.Index,
+			Equals: options.Equals,
+			Has:    options.Has,
+			Limit:  options.Limit,
+		}})
+		if err != nil {
+			return nil, http.StatusBadGateway, fmt.Errorf("error querying edv documents from %s using mercury client. %w", vaultURL, err)
+		}
+		if res.StatusCode >= http.StatusBadRequest {
+			return nil, res.StatusCode, fmt.Errorf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+		}
+		return res.Documents, res.StatusCode, nil
+	}
+	documents := []mercury.Document{}
+	for _, id := range options.DocumentIDs {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		res, err := client.mercuryRequest(ctx, mercury.OperationDownload, vaultURL+"/documents/"+url.PathEscape(id), mercury.RequestOptions{})
+		if err != nil {
+			return nil, http.StatusBadGateway, fmt.Errorf("error downloading edv document %s using mercury client. %w", id, err)
+		}
+		if res.StatusCode >= http.StatusBadRequest {
+			return nil, res.StatusCode, fmt.Errorf("error downloading edv document %s. %d %s", id, res.StatusCode, http.StatusText(res.StatusCode))
+		}
+		if len(res.Documents) == 0 {
+			documents = append(documents, mercury.Document{ID: id, Content: res.Content})
+			continue
+		}
+		documents = append(documents, res.Documents...)
+	}
+	return documents, http.StatusOK, nil
+}
+
+func getEDVFrame(ctx context.Context, frame *data.Frame, documents []mercury.Document, query models.Query) (*data.Frame, error) {
+	ids := make([]string, len(documents))
+	sequences := make([]int64, len(documents))
+	metas := make([]json.RawMessage, len(documents))
+	for i, document := range documents {
+		ids[i] = document.ID
+		sequences[i] = document.Sequence
+		metas[i] = document.Meta
+		if len(metas[i]) == 0 {
+			metas[i] = json.RawMessage("{}")
+		}
+	}
+	frame.Fields = append(frame.Fields, data.NewField("id", nil, ids), data.NewField("sequence", nil, sequences), data.NewField("meta", nil, metas))
+	columns := []jsonframer.ColumnSelector{}
+	for _, c := range query.Columns {
+		columns = append(columns, jsonframer.ColumnSelector{
+			Selector:   c.Selector,
+			Alias:      c.Text,
+			Type:       c.Type,
+			TimeFormat: c.TimeStampFormat,
+		})
+	}
+	// The content of each document is framed separately so that the values always line up with the document row.
+	// Values missing in a document are null and the documents which can't be aligned are reported as a notice.
+	contentFields := []*data.Field{}
+	contentFieldsByName := map[string]*data.Field{}
+	skippedDocuments := []string{}
+	for i, document := range documents {
+		content := document.Content
+		if len(content) == 0 {
+			content = json.RawMessage("{}")
+		}
+		contentFrame, err := jsonframer.ToFrame("["+string(content)+"]", jsonframer.FramerOptions{FrameName: query.RefID, Columns: columns})
+		if err != nil {
+			return frame, fmt.Errorf("error parsing edv document content. %w", err)
+		}
+		if contentFrame == nil {
+			continue
+		}
+		skipped := false
+		for _, field := range contentFrame.Fields {
+			if field.Len() != 1 {
+				skipped = true
+				continue
+			}
+			contentField, ok := contentFieldsByName[field.Name]
+			if !ok {
+				contentField = data.NewFieldFromFieldType(field.Type().NullableType(), len(documents))
+				contentField.Name = field.Name
+				contentField.Labels = field.Labels
+				contentField.Config = field.Config
+				contentFieldsByName[field.Name] = contentField
+				contentFields = append(contentFields, contentField)
+			}
+			if contentField.Type() != field.Type().NullableType() {
+				skipped = true
+				continue
+			}
+			if value, ok := field.ConcreteAt(0); ok {
+				contentField.SetConcrete(i, value)
+			}
+		}
+		if skipped {
+			skippedDocuments = append(skippedDocuments, document.ID)
+		}
+	}
+	frame.Fields = append(frame.Fields, contentFields...)
+	frame, err := PostProcessFrame(ctx, frame, query)
+	if frame != nil && len(skippedDocuments) > 0 {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("some values of the edv documents %s are shown as null because their content doesn't match the other documents", strings.Join(skippedDocuments, ", ")),
+		})
+	}
+	return frame, err
+}
@@ -0,0 +1,111 @@
+package infinity_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestEDVDocuments(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake mercury client adapter script requires a posix shell")
+	}
+	script := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *'"operation":"query"'*'"index":"urn:foo"'*) echo '{"status":200,"documents":[{"id":"z1","sequence":2,"meta":{"type":"user"},"content":{"name":"foo","age":20}},{"id":"z2","sequence":0,"content":{"name":"bar","age":30}}]}' ;;
+  *'"operation":"query"'*'"index":"urn:bar"'*) echo '{"status":200,"documents":[{"id":"z1","content":{"name":"foo","age":20}},{"id":"z2","content":{"name":10}}]}' ;;
+  *'"operation":"download"'*'/edvs/z123/documents/z3'*) echo '{"status":200,"content":{"name":"baz","age":40}}' ;;
+  *) echo '{"status":404}' ;;
+esac
+`
+	adapterPath := filepath.Join(t.TempDir(), "fake-mercury-client")
+	require.Nil(t, os.WriteFile(adapterPath, []byte(script), 0o755))
+	t.Setenv(models.EnvZCapAdapterAllowedPaths, adapterPath)
+	client := newTestClient(t, models.InfinitySettings{
+		AuthenticationMethod: models.AuthenticationMethodZCAP,
+		ZCapSettings:         models.ZCapSettings{AdapterPath: adapterPath},
+	})
+	t.Run("should list the documents matching the index", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(`{ "type": "json", "source": "edv", "url": "https://foo.com/edvs/z123/", "edv_options": { "index": "urn:foo", "has": ["name"] } }`),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		idField, _ := frame.FieldByName("id")
+		require.NotNil(t, idField)
+		assert.Equal(t, "z1", idField.At(0))
+		sequenceField, _ := frame.FieldByName("sequence")
+		require.NotNil(t, sequenceField)
+		assert.Equal(t, int64(2), sequenceField.At(0))
+		metaField, _ := frame.FieldByName("meta")
+		require.NotNil(t, metaField)
+		assert.Equal(t, json.RawMessage(`{"type":"user"}`), metaField.At(0))
+		nameField, _ := frame.FieldByName("name")
+		require.NotNil(t, nameField)
+		assert.Equal(t, "bar", *(nameField.At(1).(*string)))
+		assert.Equal(t, http.StatusOK, frame.Meta.Custom.(*infinity.CustomMeta).ResponseCodeFromServer)
+	})
+	t.Run("should keep the values aligned with the documents when the content differs", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(`{ "type": "json", "source": "edv", "url": "https://foo.com/edvs/z123", "edv_options": { "index": "urn:bar" } }`),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		nameField, _ := frame.FieldByName("name")
+		require.NotNil(t, nameField)
+		assert.Equal(t, "foo", *(nameField.At(0).(*string)))
+		assert.Nil(t, nameField.At(1))
+		ageField, _ := frame.FieldByName("age")
+		require.NotNil(t, ageField)
+		assert.Equal(t, float64(20), *(ageField.At(0).(*float64)))
+		assert.Nil(t, ageField.At(1))
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Contains(t, frame.Meta.Notices[0].Text, "z2")
+	})
+	t.Run("should fetch the documents by id", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(`{ "type": "json", "source": "edv", "url": "https://foo.com/edvs/z123", "edv_options": { "document_ids": ["z3"] } }`),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		idField, _ := frame.FieldByName("id")
+		assert.Equal(t, "z3", idField.At(0))
+		ageField, _ := frame.FieldByName("age")
+		require.NotNil(t, ageField)
+		assert.Equal(t, float64(40), *(ageField.At(0).(*float64)))
+	})
+	t.Run("should report the missing documents", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(`{ "type": "json", "source": "edv", "url": "https://foo.com/edvs/z123", "edv_options": { "document_ids": ["missing"] } }`),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, "error downloading edv document missing. 404 Not Found", res.Error.Error())
+	})
+	t.Run("should error with native zcap mode", func(t *testing.T) {
+		nativeClient := *client
+		nativeClient.Settings.ZCapSettings.Mode = models.ZCapModeNative
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(`{ "type": "json", "source": "edv", "url": "https://foo.com/edvs/z123" }`),
+		}, nativeClient, map[string]string{})
+		require.NotNil(t, res)
+		require.NotNil(t, res.Error)
+	})
+}
@@ -0,0 +1,55 @@
+package infinity_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+// newTestServer starts the test server which is closed once the test completes
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestTLSServer starts the test server with the self signed certificate which is closed once the test completes
+func newTestTLSServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestClient returns the client of the settings which is disposed once the test completes
+func newTestClient(t *testing.T, settings models.InfinitySettings) *infinity.Client {
+	t.Helper()
+	client, err := infinity.NewClient(context.TODO(), settings)
+	require.Nil(t, err)
+	t.Cleanup(client.Dispose)
+	return client
+}
+
+// queryData loads the query and returns the frame of the url or edv source same as the query data handler
+func queryData(ctx context.Context, query backend.DataQuery, client infinity.Client, requestHeaders map[string]string) backend.DataResponse {
+	q, err := models.LoadQuery(models.WithPaginationMaxPages(ctx, client.Settings.PaginationMaxPages), query, backend.PluginContext{})
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	var frame *data.Frame
+	switch q.Source {
+	case "edv":
+		frame, err = infinity.GetFrameForEDVSources(ctx, q, client)
+	default:
+		frame, err = infinity.GetFrameForURLSources(ctx, q, client, requestHeaders)
+	}
+	return backend.DataResponse{Frames: data.Frames{frame}, Error: err}
+}
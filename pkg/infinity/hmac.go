@@ -0,0 +1,151 @@
+package infinity
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+// ApplyHMACAuth signs the request with the configured HMAC secret.
+// This must be applied after all the other headers are set, as the signature may depend on them.
+func ApplyHMACAuth(settings models.InfinitySettings, req *http.Request, includeSect bool) *http.Request {
+	if settings.AuthenticationMethod != models.AuthenticationMethodHMAC || req == nil {
+		return req
+	}
+	hmacSettings := settings.HMACSettings
+	timestamp := getHMACTimestamp(time.Now(), hmacSettings.TimestampFormat)
+	if hmacSettings.TimestampHeader != "" {
+		req.Header.Set(hmacSettings.TimestampHeader, timestamp)
+	}
+	if hmacSettings.SignatureHeader == "" {
+		return req
+	}
+	if !includeSect {
+		req.Header.Set(hmacSettings.SignatureHeader, hmacSettings.SignaturePrefix+dummyHeader)
+		return req
+	}
+	body, err := getRequestBody(req)
+	if err != nil {
+		backend.Logger.Error("error reading request body for hmac signature", "error", err.Error())
+		return req
+	}
+	signature, err := GetHMACSignature(hmacSettings, GetHMACStringToSign(hmacSettings, req, timestamp, body))
+	if err != nil {
+		backend.Logger.Error("error computing hmac signature", "error", err.Error())
+		return req
+	}
+	req.Header.Set(hmacSettings.SignatureHeader, hmacSettings.SignaturePrefix+signature)
+	return req
+}
+
+// GetHMACStringToSign interpolates the string to sign template with the values from the request
+func GetHMACStringToSign(hmacSettings models.HMACSettings, req *http.Request, timestamp string, body []byte) string {
+	stringToSign := hmacSettings.StringToSign
+	if stringToSign == "" {
+		stringToSign = models.HMACDefaultStringToSign
+	}
+	bodyHash := []byte{}
+	if h := getHMACHashFunc(hmacSettings.Algorithm); h != nil {
+		hasher := h()
+		hasher.Write(body)
+		bodyHash = hasher.Sum(nil)
+	}
+	return strings.NewReplacer(
+		"\\n", "\n",
+		"${method}", req.Method,
+		"${path}", req.URL.EscapedPath(),
+		"${query}", req.URL.RawQuery,
+		"${uri}", req.URL.RequestURI(),
+		"${host}", req.URL.Host,
+		"${timestamp}", timestamp,
+		"${body}", string(body),
+		"${bodyHashBase64}", encodeHMACValue(bodyHash, models.HMACEncodingBase64),
+		"${bodyHash}", encodeHMACValue(bodyHash, models.HMACEncodingHex),
+	).Replace(stringToSign)
+}
+
+// GetHMACSignature returns the encoded HMAC of the given message
+func GetHMACSignature(hmacSettings models.HMACSettings, message string) (string, error) {
+	h := getHMACHashFunc(hmacSettings.Algorithm)
+	if h == nil {
+		return "", fmt.Errorf("invalid hmac algorithm %s", hmacSettings.Algorithm)
+	}
+	secret := []byte(hmacSettings.Secret)
+	if hmacSettings.SecretEncoding == "base64" {
+		decodedSecret, err := base64.StdEncoding.DecodeString(hmacSettings.Secret)
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 encoded hmac secret. %w", err)
+		}
+		secret = decodedSecret
+	}
+	mac := hmac.New(h, secret)
+	mac.Write([]byte(message))
+	return encodeHMACValue(mac.Sum(nil), hmacSettings.Encoding), nil
+}
+
+func getHMACHashFunc(algorithm models.HMACAlgorithm) func() hash.Hash {
+	switch algorithm {
+	case models.HMACAlgorithmSHA1:
+		return sha1.New
+	case models.HMACAlgorithmSHA512:
+		return sha512.New
+	case models.HMACAlgorithmSHA256, "":
+		return sha256.New
+	default:
+		return nil
+	}
+}
+
+func encodeHMACValue(input []byte, encoding models.HMACEncoding) string {
+	if encoding == models.HMACEncodingBase64 {
+		return base64.StdEncoding.EncodeToString(input)
+	}
+	return hex.EncodeToString(input)
+}
+
+func getHMACTimestamp(t time.Time, format models.HMACTimestampFormat) string {
+	switch format {
+	case models.HMACTimestampFormatUnixMilli:
+		return fmt.Sprintf("%d", t.UnixMilli())
+	case models.HMACTimestampFormatRFC3339:
+		return t.UTC().Format(time.RFC3339)
+	case models.HMACTimestampFormatRFC1123:
+		return t.UTC().Format(http.TimeFormat)
+	default:
+		return fmt.Sprintf("%d", t.Unix())
+	}
+}
+
+// getRequestBody returns a copy of the request body without consuming it
+func getRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return []byte{}, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(strings.NewReader(string(b)))
+	return b, nil
+}
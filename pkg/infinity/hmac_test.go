@@ -0,0 +1,71 @@
+package infinity_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestHMACSigning(t *testing.T) {
+	t.Run("should sign each request including pages", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			body, err := io.ReadAll(r.Body)
+			require.Nil(t, err)
+			bodyHash := sha256.Sum256(body)
+			mac := hmac.New(sha256.New, []byte("my-secret"))
+			mac.Write([]byte(strings.Join([]string{r.Header.Get("X-Timestamp"), r.Method, r.URL.RequestURI(), hex.EncodeToString(bodyHash[:])}, "|")))
+			if r.Header.Get("X-Signature") != "v1="+base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "my-key", r.Header.Get("X-Api-Key"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `[{"foo":"bar"}]`)
+		})
+		client := newTestClient(t, models.InfinitySettings{
+			URL:                  server.URL,
+			AllowedHosts:         []string{server.URL},
+			AuthenticationMethod: models.AuthenticationMethodHMAC,
+			CustomHeaders:        map[string]string{"X-Api-Key": "my-key"},
+			HMACSettings: models.HMACSettings{
+				StringToSign:    "${timestamp}|${method}|${uri}|${bodyHash}",
+				Algorithm:       models.HMACAlgorithmSHA256,
+				Encoding:        models.HMACEncodingBase64,
+				TimestampFormat: models.HMACTimestampFormatUnixMilli,
+				TimestampHeader: "X-Timestamp",
+				SignatureHeader: "X-Signature",
+				SignaturePrefix: "v1=",
+				Secret:          "my-secret",
+			},
+		})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{
+				"type": "json",
+				"source": "url",
+				"parser": "backend",
+				"url":  "%s/orders?symbol=BTC",
+				"url_options": { "method": "POST", "data": "{\"side\":\"buy\"}" },
+				"pagination_mode": "page",
+				"pagination_max_pages": 2
+			}`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, requests)
+		require.Equal(t, 2, res.Frames[0].Rows())
+	})
+}
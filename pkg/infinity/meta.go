@@ -15,6 +15,8 @@ type CustomMeta struct {
 	ResponseCodeFromServer int           `json:"responseCodeFromServer"`
 	Duration               time.Duration `json:"duration"`
 	Error                  string        `json:"error"`
+	// RateLimitWait is the total time the requests of the query waited for the rate limits of the datasource
+	RateLimitWait time.Duration `json:"rateLimitWait,omitempty"`
 }
 
 func GetDummyFrame(query models.Query) *data.Frame {
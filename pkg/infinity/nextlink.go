@@ -0,0 +1,94 @@
+package infinity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+type responseHeadersKey struct{}
+
+type responseHeaders struct {
+	header http.Header
+}
+
+// withResponseHeaders returns the context which captures the headers of the response. Used by the pagination modes which depend on the response headers
+func withResponseHeaders(ctx context.Context) (context.Context, *responseHeaders) {
+	headers := &responseHeaders{header: http.Header{}}
+	return context.WithValue(ctx, responseHeadersKey{}, headers), headers
+}
+
+func setResponseHeaders(ctx context.Context, header http.Header) {
+	if headers, ok := ctx.Value(responseHeadersKey{}).(*responseHeaders); ok {
+		headers.header = header.Clone()
+	}
+}
+
+/*
+ * getNextLink returns the link of the next page.
+ *
+ * When the extraction path is configured, the link is extracted from the response body. Otherwise the link with
+ * rel="next" of the `Link` response header (RFC 8288) is used. For example
+ *
+ *		Link: <https://api.github.com/repositories/1/issues?page=2>; rel="next", <https://api.github.com/repositories/1/issues?page=5>; rel="last"
+ *
+ * Empty link is returned when there is no next page
+ */
+func getNextLink(query models.Query, responseObject any, header http.Header) (string, error) {
+	if extractionPath := strings.TrimSpace(query.PageParamNextLinkExtractionPath); extractionPath != "" {
+		// missing path is the last page
+		link, err := getResponseValue(query, responseObject, extractionPath)
+		if err != nil || link == "null" {
+			return "", nil
+		}
+		return strings.TrimSpace(link), nil
+	}
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			target, params, found := strings.Cut(strings.TrimSpace(link), ";")
+			target = strings.TrimSpace(target)
+			if !found || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">"), nil
+					}
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// getNextLinkURL resolves the next link against the url of the current page and verifies the next url is allowed
+func getNextLinkURL(settings models.InfinitySettings, currentURL string, nextLink string) (string, error) {
+	if !strings.HasPrefix(currentURL, settings.URL) {
+		currentURL = settings.URL + currentURL
+	}
+	base, err := url.Parse(currentURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %s. %w", currentURL, err)
+	}
+	next, err := url.Parse(nextLink)
+	if err != nil {
+		return "", fmt.Errorf("invalid next link %s. %w", nextLink, err)
+	}
+	nextURL := base.ResolveReference(next).String()
+	if !strings.HasPrefix(nextURL, settings.URL) {
+		return "", fmt.Errorf("next link %s is not under the datasource url", nextURL)
+	}
+	if !CanAllowURL(nextURL, settings.AllowedHosts) {
+		return "", fmt.Errorf("next link %s is not in the allowed hosts", nextURL)
+	}
+	return nextURL, nil
+}
@@ -0,0 +1,84 @@
+package infinity_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestNextLinkPagination(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		switch r.URL.Path {
+		case "/header":
+			if page == "" {
+				w.Header().Set("Link", `</header?page=2>; rel="next", </header?page=3>; rel="last"`)
+			}
+			if page == "2" {
+				w.Header().Add("Link", `<http://`+r.Host+`/header?page=3>; rel="next"`)
+			}
+			_, _ = io.WriteString(w, fmt.Sprintf(`[{"page":"%s"}]`, page))
+		case "/body":
+			if page == "2" {
+				_, _ = io.WriteString(w, `{ "items": [{"page":"2"}], "links": { "next": null } }`)
+				return
+			}
+			_, _ = io.WriteString(w, `{ "items": [{"page":"1"}], "links": { "next": "body?page=2" } }`)
+		case "/external":
+			w.Header().Set("Link", `<https://example.com/external?page=2>; rel="next"`)
+			_, _ = io.WriteString(w, `[{"page":"1"}]`)
+		}
+	})
+	getPages := func(t *testing.T, frame *data.Frame) []string {
+		t.Helper()
+		field, _ := frame.FieldByName("page")
+		require.NotNil(t, field)
+		pages := []string{}
+		for i := 0; i < field.Len(); i++ {
+			if v, ok := field.ConcreteAt(i); ok {
+				pages = append(pages, fmt.Sprintf("%v", v))
+			}
+		}
+		return pages
+	}
+	t.Run("should follow the next link from the link header", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/header", "pagination_mode": "next_link", "pagination_max_pages": 5 }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, []string{"", "2", "3"}, getPages(t, res.Frames[0]))
+	})
+	t.Run("should stop at the max pages", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/header", "pagination_mode": "next_link", "pagination_max_pages": 2 }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, []string{"", "2"}, getPages(t, res.Frames[0]))
+	})
+	t.Run("should follow the relative next link from the response body", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/body", "root_selector": "items", "pagination_mode": "next_link", "pagination_max_pages": 5, "pagination_param_next_link_extraction_path": "links.next" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, []string{"1", "2"}, getPages(t, res.Frames[0]))
+	})
+	t.Run("should not follow the next link outside of the allowed hosts", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{AllowedHosts: []string{server.URL}})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/external", "pagination_mode": "next_link", "pagination_max_pages": 5 }`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "next link https://example.com/external?page=2 is not in the allowed hosts")
+	})
+}
@@ -70,6 +70,7 @@ func ApplyDigestAuth(ctx context.Context, httpClient *http.Client, settings mode
 	defer span.End()
 	if settings.AuthenticationMethod == models.AuthenticationMethodDigestAuth {
 		a := dac.NewTransport(settings.UserName, settings.Password)
+		a.HTTPClient = &http.Client{Transport: httpClient.Transport, Timeout: httpClient.Timeout}
 		httpClient.Transport = &a
 	}
 	return httpClient
@@ -78,7 +79,7 @@ func ApplyAWSAuth(ctx context.Context, httpClient *http.Client, settings models.
 	ctx, span := tracing.DefaultTracer().Start(ctx, "ApplyAWSAuth")
 	defer span.End()
 	if settings.AuthenticationMethod == models.AuthenticationMethodAWS {
-		tempHttpClient := getBaseHTTPClient(ctx, settings)
+		baseTransport := httpClient.Transport
 		authType := settings.AWSSettings.AuthType
 		if authType == "" {
 			authType = models.AWSAuthTypeKeys
@@ -100,7 +101,7 @@ func ApplyAWSAuth(ctx context.Context, httpClient *http.Client, settings models.
 		}
 		rt, _ := sigv4.New(conf, sigv4.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
 			req.Header.Add("Accept", "application/json")
-			return tempHttpClient.Do(req)
+			return baseTransport.RoundTrip(req)
 		}))
 		httpClient.Transport = rt
 	}
@@ -0,0 +1,523 @@
+package infinity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	xj "github.com/basgys/goxml2json"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/tidwall/gjson"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+	"github.com/yesoreyeram/grafana-plugins/lib/go/jsonframer"
+)
+
+// maximum number of the pages fetched concurrently
+const paginationMaxParallelism = 10
+
+// canPaginate returns true when the query type supports the pagination. The pages of the backend parser are merged as frames while the raw pages of the uql and groq queries are merged before the uql or groq query is evaluated
+func canPaginate(query models.Query) bool {
+	if query.PageMode == models.PaginationModeNone || query.PageMode == "" {
+		return false
+	}
+	if query.Parser != models.InfinityParserBackend && !isRawPagination(query) {
+		return false
+	}
+	if query.PageMode == models.PaginationModeRelay {
+		return query.Type == models.QueryTypeGraphQL
+	}
+	switch query.Type {
+	case models.QueryTypeJSON, models.QueryTypeGraphQL, models.QueryTypeCSV, models.QueryTypeTSV, models.QueryTypeXML, models.QueryTypeHTML:
+		return true
+	case models.QueryTypeUQL, models.QueryTypeGROQ:
+		return true
+	default:
+		return false
+	}
+}
+
+// getPaginationMaxPages returns the max pages of the query limited by the pagination max pages of the datasource
+func getPaginationMaxPages(settings models.InfinitySettings, query models.Query) int {
+	return min(max(query.PageMaxPages, 1), models.GetPaginationMaxPages(settings.PaginationMaxPages))
+}
+
+// paginationState keeps track of the fetched pages to decide when to stop requesting the next pages
+type paginationState struct {
+	query            models.Query
+	rows             int
+	lastResponseHash [sha256.Size]byte
+	pages            int
+	failures         []pageFailure
+}
+
+// pageFailure is the failed page of the pagination with `pagination_partial_results` enabled
+type pageFailure struct {
+	page   int
+	status int
+	err    error
+}
+
+/*
+ * failed records the failed page and returns nil when the partial results are enabled, so that the frames of the other pages are returned with a notice of the failed page.
+ * Otherwise the error of the page is returned and the query fails. Cancelled pages always fail the query
+ */
+func (p *paginationState) failed(pageIndex int, frame *data.Frame, err error) error {
+	if err == nil {
+		return nil
+	}
+	if !p.query.PagePartialResults || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	failure := pageFailure{page: pageIndex + 1, err: err}
+	if frame != nil && frame.Meta != nil {
+		if customMeta, ok := frame.Meta.Custom.(*CustomMeta); ok {
+			failure.status = customMeta.ResponseCodeFromServer
+		}
+	}
+	p.failures = append(p.failures, failure)
+	return nil
+}
+
+// applyMeta adds the page count statistics and a warning notice per failed page to the merged frame
+func (p *paginationState) applyMeta(frame *data.Frame) *data.Frame {
+	if frame == nil {
+		return frame
+	}
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.Stats = append(frame.Meta.Stats,
+		data.QueryStat{FieldConfig: data.FieldConfig{DisplayName: "Pages fetched"}, Value: float64(p.pages)},
+		data.QueryStat{FieldConfig: data.FieldConfig{DisplayName: "Pages failed"}, Value: float64(len(p.failures))},
+	)
+	for _, failure := range p.failures {
+		text := fmt.Sprintf("Page %d failed with status %d. %s", failure.page, failure.status, failure.err.Error())
+		switch p.query.PageMode {
+		case models.PaginationModeCursor, models.PaginationModeNextLink, models.PaginationModeRelay:
+			// the next page can't be requested without the response of the failed page
+			text = fmt.Sprintf("Page %d failed with status %d and the next pages are not requested. %s", failure.page, failure.status, failure.err.Error())
+		}
+		frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: text})
+	}
+	return frame
+}
+
+/*
+ * next records the page and returns whether the page should be included in the results and whether to request the next page.
+ *
+ * All the pagination modes stop once the total rows limit of the query is reached. Cursor mode additionally stops when the has more
+ * field of the response is false or missing. Offset and page modes additionally stop when
+ *		- the page is empty, when `pagination_stop_on_empty_page` is enabled
+ *		- the response is identical to the previous page, when `pagination_stop_on_duplicate_page` is enabled. The identical page is not included in the results
+ *		- the page has fewer rows than the page size, when `pagination_stop_on_partial_page` is enabled
+ *		- the has more field of the response is false or missing
+ *		- the total field of the response is less than or equal to the number of the fetched items
+ *
+ * Empty pages of the offset and page modes are not included in the results unless it is the first page, as they can't be merged with the pages having fields
+ */
+func (p *paginationState) next(frame *data.Frame, pageIndex int) (include bool, next bool) {
+	p.pages++
+	rows := getPageRows(p.query, frame)
+	responseObject := getResponseObject(frame)
+	if p.query.PageMode == models.PaginationModeCursor {
+		p.rows += rows
+		return true, (p.query.PageMaxRows <= 0 || p.rows < p.query.PageMaxRows) && p.hasMore(responseObject)
+	}
+	if p.query.PageMode != models.PaginationModeOffset && p.query.PageMode != models.PaginationModePage {
+		p.rows += rows
+		return true, p.query.PageMaxRows <= 0 || p.rows < p.query.PageMaxRows
+	}
+	body, _ := json.Marshal(responseObject)
+	responseHash := sha256.Sum256(body)
+	if p.query.PageStopOnDuplicatePage && pageIndex > 0 && responseHash == p.lastResponseHash {
+		return false, false
+	}
+	p.lastResponseHash = responseHash
+	p.rows += rows
+	// empty pages can't be merged with the pages having fields
+	include = rows > 0 || pageIndex == 0
+	if rows == 0 && p.query.PageStopOnEmptyPage {
+		return include, false
+	}
+	if p.query.PageMaxRows > 0 && p.rows >= p.query.PageMaxRows {
+		return include, false
+	}
+	if p.query.PageStopOnPartialPage && rows < p.query.PageParamSizeFieldVal {
+		return include, false
+	}
+	if !p.hasMore(responseObject) {
+		return include, false
+	}
+	if path := strings.TrimSpace(p.query.PageParamTotalExtractionPath); path != "" {
+		total, err := getResponseValue(p.query, responseObject, path)
+		if err != nil {
+			return include, false
+		}
+		totalItems, err := strconv.ParseFloat(strings.TrimSpace(total), 64)
+		if err != nil {
+			return include, false
+		}
+		fetchedItems := (pageIndex + 1) * p.query.PageParamSizeFieldVal
+		if p.query.PageMode == models.PaginationModeOffset {
+			fetchedItems += p.query.PageParamOffsetFieldVal
+		}
+		if float64(fetchedItems) >= totalItems {
+			return include, false
+		}
+	}
+	return include, true
+}
+
+// hasMore returns false when the has more field of the response is false or missing. Always true when the has more path is not configured
+func (p *paginationState) hasMore(responseObject any) bool {
+	path := strings.TrimSpace(p.query.PageParamHasMoreExtractionPath)
+	if path == "" {
+		return true
+	}
+	hasMore, err := getResponseValue(p.query, responseObject, path)
+	if err != nil {
+		return false
+	}
+	v, err := strconv.ParseBool(strings.TrimSpace(hasMore))
+	return err == nil && v
+}
+
+func getResponseObject(frame *data.Frame) any {
+	if frame == nil || frame.Meta == nil {
+		return nil
+	}
+	if customMeta, ok := frame.Meta.Custom.(*CustomMeta); ok {
+		return customMeta.Data
+	}
+	return nil
+}
+
+// limitFrameRows removes the rows of the frame beyond the total rows limit of the query
+func limitFrameRows(frame *data.Frame, maxRows int) *data.Frame {
+	if frame == nil || maxRows <= 0 {
+		return frame
+	}
+	for frame.Rows() > maxRows {
+		frame.DeleteRow(frame.Rows() - 1)
+	}
+	return frame
+}
+
+type pageResult struct {
+	frame *data.Frame
+	err   error
+	done  chan struct{}
+}
+
+/*
+ * getPageFrames fetches the pages of the offset, page and list modes, which are known up-front. Up to `pagination_parallelism`
+ * pages are fetched concurrently. The frames are returned in the page order, up to the page which meets the stop condition.
+ * The outstanding pages are cancelled as soon as a page fails or meets the stop condition. With the partial results, failed pages are skipped
+ */
+func getPageFrames(ctx context.Context, queries []models.Query, infClient Client, requestHeaders map[string]string, pagination *paginationState) ([]*data.Frame, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	parallelism := min(max(pagination.query.PageParallelism, 1), paginationMaxParallelism)
+	results := make([]*pageResult, len(queries))
+	for i := range results {
+		results[i] = &pageResult{done: make(chan struct{})}
+	}
+	// the slot of the page is released once the page is evaluated so that no page is requested beyond the stop condition without parallelism
+	slots := make(chan struct{}, parallelism)
+	go func() {
+		for i, query := range queries {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				for _, result := range results[i:] {
+					result.err = ctx.Err()
+					close(result.done)
+				}
+				return
+			}
+			go func(result *pageResult, query models.Query) {
+				result.frame, _, result.err = GetFrameForURLSourcesWithPostProcessing(ctx, query, infClient, requestHeaders, false)
+				close(result.done)
+			}(results[i], query)
+		}
+	}()
+	frames := []*data.Frame{}
+	for pageIndex, result := range results {
+		<-result.done
+		if result.err != nil {
+			if err := pagination.failed(pageIndex, result.frame, result.err); err != nil {
+				return append(frames, result.frame), err
+			}
+			<-slots
+			continue
+		}
+		include, next := pagination.next(result.frame, pageIndex)
+		if include {
+			frames = append(frames, result.frame)
+		}
+		if !next {
+			break
+		}
+		<-slots
+	}
+	return frames, nil
+}
+
+/*
+ * getResponseValue returns the value of the selector from the response. Used for the cursor, next link, has more and total values of the pagination.
+ *
+ *		json, graphql	gjson or jsonata selector of the response, for example `meta.next_cursor` or `data.users.pageInfo.endCursor`
+ *		xml, html		selector of the xml converted to json same as the root selector, for example `feed.cursor`, or absolute XPath
+ *						of the element or attribute, for example `/feed/cursor`, `/feed/link[2]/@href` or `/feed/cursor/text()`
+ *		csv, tsv		name of the header column. The value is taken from the trailing row of the response, for example `next_cursor`.
+ *						Columns of the responses without the header row are named by their position, for example `3`
+ */
+func getResponseValue(query models.Query, responseObject any, selector string) (string, error) {
+	switch query.Type {
+	case models.QueryTypeCSV, models.QueryTypeTSV:
+		responseString, ok := responseObject.(string)
+		if !ok {
+			return "", errors.New("invalid csv response")
+		}
+		header, records, err := readCSVRecords(query, responseString)
+		if err != nil {
+			return "", err
+		}
+		// the response without the rows is the last page
+		if len(records) == 0 {
+			return "", nil
+		}
+		columnIndex := slices.Index(header, strings.TrimSpace(selector))
+		if columnIndex < 0 {
+			return "", fmt.Errorf("column %s not found in the response", selector)
+		}
+		if trailingRow := records[len(records)-1]; columnIndex < len(trailingRow) {
+			return trailingRow[columnIndex], nil
+		}
+		return "", nil
+	case models.QueryTypeXML, models.QueryTypeHTML:
+		responseString, ok := responseObject.(string)
+		if !ok {
+			return "", errors.New("invalid xml response")
+		}
+		jsonStr, err := xj.Convert(strings.NewReader(responseString))
+		if err != nil {
+			return "", fmt.Errorf("error converting xml response. %w", err)
+		}
+		result := gjson.Get(jsonStr.String(), getXPathSelector(selector))
+		if !result.Exists() {
+			return "", fmt.Errorf("%s not found in the response", selector)
+		}
+		// elements with attributes keep the text content in the #content key
+		if content := result.Get(`\#content`); result.IsObject() && content.Exists() {
+			return content.String(), nil
+		}
+		return result.String(), nil
+	default:
+		body, err := json.Marshal(responseObject)
+		if err != nil {
+			return "", err
+		}
+		return jsonframer.GetRootData(string(body), selector)
+	}
+}
+
+// readCSVRecords reads the header and the records of the csv response with the csv options of the query same as the csv backend parser
+func readCSVRecords(query models.Query, responseString string) (header []string, records [][]string, err error) {
+	noHeaders := query.CSVOptions.Columns == "-" || query.CSVOptions.Columns == "none"
+	if query.CSVOptions.Columns != "" && !noHeaders {
+		responseString = query.CSVOptions.Columns + "\n" + responseString
+	}
+	r := csv.NewReader(strings.NewReader(responseString))
+	r.LazyQuotes = true
+	if query.CSVOptions.Comment != "" {
+		r.Comment = rune(query.CSVOptions.Comment[0])
+	}
+	if query.CSVOptions.Delimiter != "" {
+		r.Comma = rune(query.CSVOptions.Delimiter[0])
+	}
+	if query.Type == models.QueryTypeTSV {
+		r.Comma = '\t'
+	}
+	if query.CSVOptions.RelaxColumnCount {
+		r.FieldsPerRecord = -1
+	}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if query.CSVOptions.SkipLinesWithError {
+				continue
+			}
+			return nil, nil, fmt.Errorf("error reading csv response. %w", err)
+		}
+		records = append(records, record)
+	}
+	if noHeaders {
+		if len(records) > 0 {
+			for i := range records[0] {
+				header = append(header, strconv.Itoa(i+1))
+			}
+		}
+		return header, records, nil
+	}
+	if len(records) == 0 {
+		return header, records, nil
+	}
+	return records[0], records[1:], nil
+}
+
+var xpathStepRegex = regexp.MustCompile(`^([^\[\]]+)(?:\[(\d+)\])?$`)
+
+// getXPathSelector converts the absolute XPath to the selector of the xml converted to json. Other selectors are returned as it is
+func getXPathSelector(selector string) string {
+	if !strings.HasPrefix(selector, "/") {
+		return selector
+	}
+	parts := []string{}
+	for _, step := range strings.Split(strings.Trim(selector, "/"), "/") {
+		switch {
+		case step == "text()":
+			// text content is unwrapped from the element
+			continue
+		case strings.HasPrefix(step, "@"):
+			parts = append(parts, "-"+strings.TrimPrefix(step, "@"))
+		default:
+			matches := xpathStepRegex.FindStringSubmatch(step)
+			if matches == nil {
+				return selector
+			}
+			parts = append(parts, matches[1])
+			// xpath positions are 1 based
+			if position, err := strconv.Atoi(matches[2]); err == nil && position > 0 {
+				parts = append(parts, strconv.Itoa(position-1))
+			}
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// getRelayNodesSelector returns the selector of the nodes of the relay connection edges
+func getRelayNodesSelector(connectionSelector string) string {
+	return strings.TrimSuffix(connectionSelector, ".") + ".edges.#.node"
+}
+
+// setGraphQLVariable returns the query with the variable set in the graphql variables of the body
+func setGraphQLVariable(query models.Query, name string, value any) (models.Query, error) {
+	variables, err := setJSONValue(query.URLOptions.BodyGraphQLVariables, []string{name}, value)
+	if err != nil {
+		return query, fmt.Errorf("invalid graphql variables. %w", err)
+	}
+	query.URLOptions.BodyGraphQLVariables = variables
+	return query, nil
+}
+
+// paginationValueType is the JSON type of the pagination value set in the json body
+type paginationValueType int
+
+const (
+	// paginationValueString is the type of the cursors and list values. The values which are explicitly JSON arrays or objects are set as JSON
+	paginationValueString paginationValueType = iota
+	// paginationValueNumber is the type of the offset, page and size values and the epoch time windows
+	paginationValueNumber
+)
+
+/*
+ * setJSONBodyValue returns the query with the value set at the path of the JSON body. For the graphql queries, the value is set in the graphql variables.
+ *
+ * Path is the dot separated keys such as `from`, `query.range.size` or `search_after`. Numeric keys are the index of the existing arrays.
+ * The JSON type of the value is decided by the value type, not by the content. So the numeric looking cursors such as `12345` stay strings.
+ * String values which are JSON arrays or objects (`["2021-01-01", 42]` for search_after) are set as JSON
+ */
+func setJSONBodyValue(query models.Query, path string, value string, valueType paginationValueType) (models.Query, error) {
+	var typedValue any = value
+	switch trimmedValue := strings.TrimSpace(value); {
+	case valueType == paginationValueNumber:
+		if _, err := strconv.ParseInt(trimmedValue, 10, 64); err == nil {
+			typedValue = json.Number(trimmedValue)
+		}
+	case strings.HasPrefix(trimmedValue, "[") || strings.HasPrefix(trimmedValue, "{"):
+		var jsonValue any
+		decoder := json.NewDecoder(strings.NewReader(trimmedValue))
+		decoder.UseNumber()
+		if err := decoder.Decode(&jsonValue); err == nil && !decoder.More() {
+			typedValue = jsonValue
+		}
+	}
+	keys := strings.Split(path, ".")
+	if query.Type == models.QueryTypeGraphQL || query.URLOptions.BodyType == "graphql" {
+		variables, err := setJSONValue(query.URLOptions.BodyGraphQLVariables, keys, typedValue)
+		if err != nil {
+			return query, fmt.Errorf("invalid graphql variables. %w", err)
+		}
+		query.URLOptions.BodyGraphQLVariables = variables
+		return query, nil
+	}
+	body, err := setJSONValue(query.URLOptions.Body, keys, typedValue)
+	if err != nil {
+		return query, fmt.Errorf("invalid json body. %w", err)
+	}
+	query.URLOptions.Body = body
+	return query, nil
+}
+
+func setJSONValue(input string, keys []string, value any) (string, error) {
+	var root any = map[string]any{}
+	if strings.TrimSpace(input) != "" {
+		decoder := json.NewDecoder(strings.NewReader(input))
+		decoder.UseNumber()
+		if err := decoder.Decode(&root); err != nil {
+			return input, err
+		}
+	}
+	root, err := setValueAtPath(root, keys, value)
+	if err != nil {
+		return input, err
+	}
+	b, err := json.Marshal(root)
+	if err != nil {
+		return input, err
+	}
+	return string(b), nil
+}
+
+func setValueAtPath(node any, keys []string, value any) (any, error) {
+	if len(keys) == 0 {
+		return value, nil
+	}
+	key := keys[0]
+	switch current := node.(type) {
+	case map[string]any:
+		child, err := setValueAtPath(current[key], keys[1:], value)
+		if err != nil {
+			return node, err
+		}
+		current[key] = child
+		return current, nil
+	case []any:
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(current) {
+			return node, fmt.Errorf("invalid array index %s", key)
+		}
+		child, err := setValueAtPath(current[index], keys[1:], value)
+		if err != nil {
+			return node, err
+		}
+		current[index] = child
+		return current, nil
+	case nil:
+		return setValueAtPath(map[string]any{}, keys, value)
+	default:
+		return node, fmt.Errorf("can't set %s of a non object value", key)
+	}
+}
@@ -0,0 +1,579 @@
+package infinity_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestPaginationLimits(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		total := 25
+		items := []string{}
+		for i := (page - 1) * size; i < min(page*size, total); i++ {
+			items = append(items, fmt.Sprintf(`{"id":%d}`, i))
+		}
+		switch r.URL.Path {
+		case "/items":
+			_, _ = io.WriteString(w, "["+strings.Join(items, ",")+"]")
+		case "/wrapped":
+			_, _ = io.WriteString(w, fmt.Sprintf(`{ "items": [%s], "has_more": %t, "total": %d }`, strings.Join(items, ","), page*size < total, total))
+		case "/same":
+			_, _ = io.WriteString(w, `[{"id":1},{"id":2}]`)
+		}
+	})
+	runQuery := func(t *testing.T, settings models.InfinitySettings, queryJSON string) (backend.DataResponse, int) {
+		t.Helper()
+		mu.Lock()
+		requests = 0
+		mu.Unlock()
+		client := newTestClient(t, settings)
+		res := queryData(context.Background(), backend.DataQuery{JSON: []byte(strings.ReplaceAll(queryJSON, "${url}", server.URL))}, *client, map[string]string{})
+		mu.Lock()
+		defer mu.Unlock()
+		return res, requests
+	}
+	t.Run("should limit the max pages by the datasource pagination max pages", func(t *testing.T) {
+		query := `{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/same", "pagination_mode": "list", "pagination_param_list_field_name": "id", "pagination_param_list_value": "1,2,3,4,5,6,7,8,9,10", "pagination_max_pages": 10 }`
+		res, requests := runQuery(t, models.InfinitySettings{}, query)
+		require.Nil(t, res.Error)
+		require.Equal(t, 5, requests)
+		res, requests = runQuery(t, models.InfinitySettings{PaginationMaxPages: 20}, query)
+		require.Nil(t, res.Error)
+		require.Equal(t, 10, requests)
+		require.Equal(t, 20, res.Frames[0].Rows())
+	})
+	t.Run("should stop on the empty page", func(t *testing.T) {
+		res, requests := runQuery(t, models.InfinitySettings{PaginationMaxPages: 100}, `{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/items", "pagination_mode": "page", "pagination_param_size_value": 10, "pagination_max_pages": 100, "pagination_stop_on_empty_page": true }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, 4, requests)
+		require.Equal(t, 25, res.Frames[0].Rows())
+	})
+	t.Run("should not stop on the empty page unless the stop condition is enabled", func(t *testing.T) {
+		res, requests := runQuery(t, models.InfinitySettings{PaginationMaxPages: 100}, `{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/items", "pagination_mode": "page", "pagination_param_size_value": 10, "pagination_max_pages": 6 }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, 6, requests)
+		require.Equal(t, 25, res.Frames[0].Rows())
+	})
+	t.Run("should stop on the partial page", func(t *testing.T) {
+		res, requests := runQuery(t, models.InfinitySettings{PaginationMaxPages: 100}, `{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/items", "pagination_mode": "page", "pagination_param_size_value": 10, "pagination_max_pages": 100, "pagination_stop_on_partial_page": true }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, 3, requests)
+		require.Equal(t, 25, res.Frames[0].Rows())
+	})
+	t.Run("should stop when the has more field is false", func(t *testing.T) {
+		res, requests := runQuery(t, models.InfinitySettings{PaginationMaxPages: 100}, `{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/wrapped", "root_selector": "items", "pagination_mode": "page", "pagination_param_size_value": 5, "pagination_max_pages": 100, "pagination_param_has_more_extraction_path": "has_more" }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, 5, requests)
+		require.Equal(t, 25, res.Frames[0].Rows())
+	})
+	t.Run("should stop when the total items are fetched", func(t *testing.T) {
+		res, requests := runQuery(t, models.InfinitySettings{PaginationMaxPages: 100}, `{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/wrapped", "root_selector": "items", "pagination_mode": "page", "pagination_param_size_value": 10, "pagination_max_pages": 100, "pagination_param_total_extraction_path": "total" }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, 3, requests)
+		require.Equal(t, 25, res.Frames[0].Rows())
+	})
+	t.Run("should stop when the response is identical to the previous page", func(t *testing.T) {
+		res, requests := runQuery(t, models.InfinitySettings{PaginationMaxPages: 100}, `{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/same", "pagination_mode": "page", "pagination_max_pages": 100, "pagination_stop_on_duplicate_page": true }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, requests)
+		require.Equal(t, 2, res.Frames[0].Rows())
+	})
+	t.Run("should stop at the max rows", func(t *testing.T) {
+		res, requests := runQuery(t, models.InfinitySettings{PaginationMaxPages: 100}, `{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/items", "pagination_mode": "page", "pagination_param_size_value": 10, "pagination_max_pages": 100, "pagination_max_rows": 15 }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, requests)
+		require.Equal(t, 15, res.Frames[0].Rows())
+	})
+}
+
+func TestParallelPages(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		switch {
+		case r.URL.Path == "/fail" && page == 2:
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		case r.URL.Path == "/fail" && page > 2:
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		case r.URL.Path == "/empty" && page > 3:
+			_, _ = io.WriteString(w, `[]`)
+			return
+		}
+		// later pages respond first
+		time.Sleep(time.Duration(10-page) * 10 * time.Millisecond)
+		_, _ = io.WriteString(w, fmt.Sprintf(`[{"page":%d}]`, page))
+	})
+	client := newTestClient(t, models.InfinitySettings{PaginationMaxPages: 100})
+	runQuery := func(queryJSON string) backend.DataResponse {
+		mu.Lock()
+		maxInFlight = 0
+		mu.Unlock()
+		return queryData(context.Background(), backend.DataQuery{JSON: []byte(strings.ReplaceAll(queryJSON, "${url}", server.URL))}, *client, map[string]string{})
+	}
+	getPages := func(t *testing.T, frame *data.Frame) []float64 {
+		t.Helper()
+		field, _ := frame.FieldByName("page")
+		require.NotNil(t, field)
+		pages := []float64{}
+		for i := 0; i < field.Len(); i++ {
+			if v, ok := field.ConcreteAt(i); ok {
+				pages = append(pages, v.(float64))
+			}
+		}
+		return pages
+	}
+	t.Run("should fetch the pages concurrently and preserve the page order", func(t *testing.T) {
+		res := runQuery(`{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/pages", "pagination_mode": "page", "pagination_max_pages": 6, "pagination_parallelism": 3 }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, []float64{1, 2, 3, 4, 5, 6}, getPages(t, res.Frames[0]))
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 3, maxInFlight)
+	})
+	t.Run("should fetch the pages one after another by default", func(t *testing.T) {
+		res := runQuery(`{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/pages", "pagination_mode": "list", "pagination_param_list_field_name": "page", "pagination_param_list_value": "1,2,3", "pagination_max_pages": 3 }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, []float64{1, 2, 3}, getPages(t, res.Frames[0]))
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 1, maxInFlight)
+	})
+	t.Run("should cancel the outstanding pages when a page fails", func(t *testing.T) {
+		startTime := time.Now()
+		res := runQuery(`{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/fail", "pagination_mode": "page", "pagination_max_pages": 10, "pagination_parallelism": 4 }`)
+		require.NotNil(t, res.Error)
+		require.Less(t, time.Since(startTime), 2*time.Second)
+	})
+	t.Run("should stop at the stop condition", func(t *testing.T) {
+		res := runQuery(`{ "type": "json", "source": "url", "parser": "backend", "url": "${url}/empty", "pagination_mode": "page", "pagination_max_pages": 20, "pagination_parallelism": 4, "pagination_stop_on_empty_page": true }`)
+		require.Nil(t, res.Error)
+		require.Equal(t, []float64{1, 2, 3}, getPages(t, res.Frames[0]))
+	})
+}
+
+func TestPaginationQueryTypes(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("page") {
+			case "1":
+				_, _ = io.WriteString(w, `[{"id":1},{"id":2}]`)
+			case "2":
+				_, _ = io.WriteString(w, `[{"id":3},{"id":4}]`)
+			default:
+				_, _ = io.WriteString(w, `[]`)
+			}
+		case "/csv-body":
+			if cursor == "c2" {
+				_, _ = io.WriteString(w, "id,next_cursor\n3,\n4,")
+				return
+			}
+			_, _ = io.WriteString(w, "id,next_cursor\n1,\n2,c2")
+		case "/csv":
+			if cursor == "c2" {
+				_, _ = io.WriteString(w, "id\n3\n4")
+				return
+			}
+			w.Header().Set("X-Next-Cursor", "c2")
+			_, _ = io.WriteString(w, "id\n1\n2")
+		case "/xml":
+			if cursor == "c2" {
+				_, _ = io.WriteString(w, `<feed><cursor/><item><id>3</id></item><item><id>4</id></item></feed>`)
+				return
+			}
+			_, _ = io.WriteString(w, `<feed><link rel="self" href="/xml"/><link rel="next" href="/xml?cursor=c2"/><cursor type="opaque">c2</cursor><item><id>1</id></item><item><id>2</id></item></feed>`)
+		case "/graphql":
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), `after: \"c2\"`) {
+				_, _ = io.WriteString(w, `{ "data": { "users": { "nodes": [{"id":3},{"id":4}], "pageInfo": { "endCursor": null } } } }`)
+				return
+			}
+			_, _ = io.WriteString(w, `{ "data": { "users": { "nodes": [{"id":1},{"id":2}], "pageInfo": { "endCursor": "c2" } } } }`)
+		}
+	})
+	client := newTestClient(t, models.InfinitySettings{})
+	tests := []struct {
+		name      string
+		queryJSON string
+	}{
+		{
+			name:      "csv cursor from the response header",
+			queryJSON: `{ "type": "csv", "source": "url", "parser": "backend", "url": "${url}/csv", "pagination_mode": "cursor", "pagination_max_pages": 5, "pagination_param_cursor_extraction_source": "header", "pagination_param_cursor_extraction_path": "x-next-cursor" }`,
+		},
+		{
+			name:      "csv cursor from the trailing row",
+			queryJSON: `{ "type": "csv", "source": "url", "parser": "backend", "url": "${url}/csv-body", "pagination_mode": "cursor", "pagination_max_pages": 5, "pagination_param_cursor_extraction_path": "next_cursor" }`,
+		},
+		{
+			name:      "xml cursor from xpath",
+			queryJSON: `{ "type": "xml", "source": "url", "parser": "backend", "url": "${url}/xml", "root_selector": "feed.item", "pagination_mode": "cursor", "pagination_max_pages": 5, "pagination_param_cursor_extraction_path": "/feed/cursor/text()" }`,
+		},
+		{
+			name:      "xml next link from xpath attribute",
+			queryJSON: `{ "type": "xml", "source": "url", "parser": "backend", "url": "${url}/xml", "root_selector": "feed.item", "pagination_mode": "next_link", "pagination_max_pages": 5, "pagination_param_next_link_extraction_path": "/feed/link[2]/@href" }`,
+		},
+		{
+			name:      "graphql cursor from the response path",
+			queryJSON: `{ "type": "graphql", "source": "url", "parser": "backend", "url": "${url}/graphql", "root_selector": "data.users.nodes", "url_options": { "method": "POST", "body_type": "graphql", "body_graphql_query": "{ users(first: 2, after: \"$cursor\") { nodes { id } pageInfo { endCursor } } }" }, "pagination_mode": "cursor", "pagination_max_pages": 5, "pagination_param_cursor_field_name": "$cursor", "pagination_param_cursor_field_type": "replace", "pagination_param_cursor_extraction_path": "data.users.pageInfo.endCursor" }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run("should paginate "+tt.name+" and post process the merged frame once", func(t *testing.T) {
+			queryJSON := strings.Replace(strings.ReplaceAll(tt.queryJSON, "${url}", server.URL), "{ ", `{ "summarizeExpression": "count(id)", `, 1)
+			res := queryData(context.Background(), backend.DataQuery{JSON: []byte(queryJSON)}, *client, map[string]string{})
+			require.Nil(t, res.Error)
+			require.Equal(t, 1, res.Frames[0].Rows())
+			count, ok := res.Frames[0].Fields[0].ConcreteAt(0)
+			require.True(t, ok)
+			require.Equal(t, float64(4), count)
+		})
+	}
+	t.Run("should merge the raw pages of the uql and groq queries", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			queryJSON string
+			want      any
+		}{
+			{
+				name:      "uql query with the json pages",
+				queryJSON: `{ "type": "uql", "source": "url", "url": "${url}/json", "uql": "parse-json", "pagination_mode": "page", "pagination_max_pages": 5, "pagination_param_size_value": 2, "pagination_stop_on_empty_page": true }`,
+				want:      []any{map[string]any{"id": float64(1)}, map[string]any{"id": float64(2)}, map[string]any{"id": float64(3)}, map[string]any{"id": float64(4)}},
+			},
+			{
+				name:      "groq parser with the json pages",
+				queryJSON: `{ "type": "json", "source": "url", "parser": "groq", "url": "${url}/json", "groq": "*", "pagination_mode": "page", "pagination_max_pages": 5, "pagination_param_size_value": 2, "pagination_stop_on_partial_page": true }`,
+				want:      []any{map[string]any{"id": float64(1)}, map[string]any{"id": float64(2)}, map[string]any{"id": float64(3)}, map[string]any{"id": float64(4)}},
+			},
+			{
+				name:      "uql parser with the csv cursor of the trailing row",
+				queryJSON: `{ "type": "csv", "source": "url", "parser": "uql", "url": "${url}/csv-body", "uql": "parse-csv", "pagination_mode": "cursor", "pagination_max_pages": 5, "pagination_param_cursor_extraction_path": "next_cursor" }`,
+				want:      "id,next_cursor\n1,\n2,c2\n3,\n4,",
+			},
+			{
+				name:      "uql parser with the xml cursor",
+				queryJSON: `{ "type": "xml", "source": "url", "parser": "uql", "url": "${url}/xml", "uql": "parse-xml", "pagination_mode": "cursor", "pagination_max_pages": 5, "pagination_param_cursor_extraction_path": "/feed/cursor/text()" }`,
+				want:      `<pages><feed><link rel="self" href="/xml"/><link rel="next" href="/xml?cursor=c2"/><cursor type="opaque">c2</cursor><item><id>1</id></item><item><id>2</id></item></feed><feed><cursor/><item><id>3</id></item><item><id>4</id></item></feed></pages>`,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				res := queryData(context.Background(), backend.DataQuery{JSON: []byte(strings.ReplaceAll(tt.queryJSON, "${url}", server.URL))}, *client, map[string]string{})
+				require.Nil(t, res.Error)
+				require.Len(t, res.Frames, 1)
+				require.Equal(t, 0, res.Frames[0].Rows())
+				customMeta, ok := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+				require.True(t, ok)
+				require.Equal(t, tt.want, customMeta.Data)
+			})
+		}
+	})
+}
+
+func TestRelayPagination(t *testing.T) {
+	var mu sync.Mutex
+	cursors := []any{}
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		cursors = append(cursors, body.Variables["after"])
+		mu.Unlock()
+		assert.Equal(t, float64(2), body.Variables["first"])
+		switch body.Variables["after"] {
+		case nil:
+			_, _ = io.WriteString(w, `{ "data": { "repository": { "issues": { "edges": [{ "node": { "id": 1, "title": "a" } }, { "node": { "id": 2, "title": "b" } }], "pageInfo": { "hasNextPage": true, "endCursor": "c2" } } } } }`)
+		case "c2":
+			_, _ = io.WriteString(w, `{ "data": { "repository": { "issues": { "edges": [{ "node": { "id": 3, "title": "c" } }], "pageInfo": { "hasNextPage": false, "endCursor": "c3" } } } } }`)
+		default:
+			_, _ = io.WriteString(w, `{ "data": { "repository": { "issues": { "edges": [], "pageInfo": { "hasNextPage": false, "endCursor": null } } } } }`)
+		}
+	})
+	client := newTestClient(t, models.InfinitySettings{})
+	t.Run("should set the cursor variable and flatten the edges until there is no next page", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "graphql", "source": "url", "parser": "backend", "url": "%s/graphql", "root_selector": "data.repository.issues", "url_options": { "method": "POST", "body_graphql_query": "query($first: Int, $after: String) { repository { issues(first: $first, after: $after) { edges { node { id title } } pageInfo { hasNextPage endCursor } } } }", "body_graphql_variables": "{ \"first\": 2 }" }, "pagination_mode": "relay", "pagination_max_pages": 5 }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 3, res.Frames[0].Rows())
+		field, _ := res.Frames[0].FieldByName("title")
+		require.NotNil(t, field)
+		title, _ := field.ConcreteAt(2)
+		require.Equal(t, "c", title)
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []any{nil, "c2"}, cursors)
+	})
+	t.Run("should require the connection path", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "graphql", "source": "url", "parser": "backend", "url": "%s/graphql", "pagination_mode": "relay" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "root_selector cannot be empty for relay pagination")
+	})
+}
+
+func TestBodyJSONPagination(t *testing.T) {
+	var mu sync.Mutex
+	bodies := []map[string]any{}
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		body := map[string]any{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/search":
+			if body["search_after"] == nil {
+				_, _ = io.WriteString(w, `{ "hits": [{ "id": 1, "sort": ["a", 1] }], "last": ["a", 1] }`)
+				return
+			}
+			_, _ = io.WriteString(w, `{ "hits": [{ "id": 2, "sort": ["b", 2] }], "last": null }`)
+		case "/graphql":
+			_, _ = io.WriteString(w, `{ "data": { "items": [{ "id": 1 }] } }`)
+		case "/tokens":
+			if body["token"] == nil {
+				_, _ = io.WriteString(w, `{ "items": [{ "id": 1 }], "next": "12345" }`)
+				return
+			}
+			_, _ = io.WriteString(w, `{ "items": [{ "id": 2 }], "next": null }`)
+		default:
+			from, _ := body["from"].(float64)
+			_, _ = io.WriteString(w, fmt.Sprintf(`[{ "id": %d }, { "id": %d }]`, int(from), int(from)+1))
+		}
+	})
+	client := newTestClient(t, models.InfinitySettings{})
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = []map[string]any{}
+	}
+	t.Run("should set the offset and size as numbers in the nested json body", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/items", "url_options": { "method": "POST", "body_type": "raw", "body_content_type": "application/json", "data": "{ \"query\": { \"match_all\": {} }, \"page\": { \"size\": 0 } }" }, "pagination_mode": "offset", "pagination_max_pages": 2, "pagination_param_size_field_name": "page.size", "pagination_param_size_field_type": "body_json", "pagination_param_size_value": 2, "pagination_param_offset_field_name": "from", "pagination_param_offset_field_type": "body_json", "pagination_param_offset_value": 0 }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 4, res.Frames[0].Rows())
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 2, len(bodies))
+		for i, body := range bodies {
+			require.Equal(t, float64(i*2), body["from"])
+			require.Equal(t, map[string]any{"size": float64(2)}, body["page"])
+			require.Equal(t, map[string]any{"match_all": map[string]any{}}, body["query"])
+		}
+	})
+	t.Run("should set the search after cursor as json array", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/search", "root_selector": "hits", "url_options": { "method": "POST", "body_type": "raw", "body_content_type": "application/json", "data": "{ \"size\": 1 }" }, "pagination_mode": "cursor", "pagination_max_pages": 5, "pagination_param_cursor_field_name": "search_after", "pagination_param_cursor_field_type": "body_json", "pagination_param_cursor_extraction_path": "last" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 2, len(bodies))
+		require.Nil(t, bodies[0]["search_after"])
+		require.Equal(t, []any{"a", float64(1)}, bodies[1]["search_after"])
+		require.Equal(t, float64(1), bodies[1]["size"])
+	})
+	t.Run("should keep the numeric looking cursor as string", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/tokens", "root_selector": "items", "url_options": { "method": "POST", "body_type": "raw", "body_content_type": "application/json", "data": "{}" }, "pagination_mode": "cursor", "pagination_max_pages": 5, "pagination_param_cursor_field_name": "token", "pagination_param_cursor_field_type": "body_json", "pagination_param_cursor_extraction_path": "next" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 2, len(bodies))
+		require.Equal(t, "12345", bodies[1]["token"])
+	})
+	t.Run("should set the graphql variables for graphql queries", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "graphql", "source": "url", "parser": "backend", "url": "%s/graphql", "root_selector": "data.items", "url_options": { "method": "POST", "body_graphql_query": "query($filter: Filter) { items(filter: $filter) { id } }", "body_graphql_variables": "{ \"filter\": { \"name\": \"foo\" } }" }, "pagination_mode": "page", "pagination_max_pages": 2, "pagination_param_page_field_name": "filter.page", "pagination_param_page_field_type": "body_json", "pagination_param_page_value": 1 }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 2, len(bodies))
+		for i, body := range bodies {
+			require.Equal(t, map[string]any{"name": "foo", "page": float64(i + 1)}, body["variables"].(map[string]any)["filter"])
+		}
+	})
+}
+
+func TestCursorPagination(t *testing.T) {
+	var mu sync.Mutex
+	cursors := []string{}
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		mu.Lock()
+		cursors = append(cursors, cursor)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/headers":
+			if cursor == "" {
+				w.Header().Set("X-Next-Token", "t2")
+			}
+			_, _ = io.WriteString(w, fmt.Sprintf(`[{ "cursor": "%s" }]`, cursor))
+		case "/has_more":
+			_, _ = io.WriteString(w, fmt.Sprintf(`{ "items": [{ "cursor": "%s" }], "next": "%s-next", "has_more": %t }`, cursor, cursor, cursor == ""))
+		default:
+			// always returns the same cursor
+			_, _ = io.WriteString(w, `{ "items": [{ "id": 1 }], "next": "same" }`)
+		}
+	})
+	client := newTestClient(t, models.InfinitySettings{PaginationMaxPages: 10})
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		cursors = []string{}
+	}
+	t.Run("should extract the cursor from the response header", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/headers", "pagination_mode": "cursor", "pagination_max_pages": 10, "pagination_param_cursor_extraction_source": "header", "pagination_param_cursor_extraction_path": "x-next-token" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []string{"", "t2"}, cursors)
+	})
+	t.Run("should require the header name for the header cursor", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/headers", "pagination_mode": "cursor", "pagination_param_cursor_extraction_source": "header" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "pagination_param_cursor_extraction_path cannot be empty for the header cursor")
+	})
+	t.Run("should stop when the has more flag is false", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/has_more", "root_selector": "items", "pagination_mode": "cursor", "pagination_max_pages": 10, "pagination_param_cursor_extraction_path": "next", "pagination_param_has_more_extraction_path": "has_more" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []string{"", "-next"}, cursors)
+	})
+	t.Run("should stop when the cursor is repeated", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/same", "root_selector": "items", "pagination_mode": "cursor", "pagination_max_pages": 10, "pagination_param_cursor_extraction_path": "next" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []string{"", "same"}, cursors)
+	})
+}
+
+func TestPartialPaginationResults(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "2" || (r.URL.Path == "/all" && page != "") {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = io.WriteString(w, `{ "error": "boom" }`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/cursor" {
+			_, _ = io.WriteString(w, `{ "items": [{ "id": 1 }], "next": "2" }`)
+			return
+		}
+		_, _ = io.WriteString(w, fmt.Sprintf(`[{ "page": "%s" }]`, page))
+	})
+	client := newTestClient(t, models.InfinitySettings{})
+	t.Run("should fail the query when a page fails by default", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/pages", "pagination_mode": "page", "pagination_max_pages": 3 }`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+	})
+	t.Run("should return the successful pages with a notice per failed page", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/pages", "pagination_mode": "page", "pagination_max_pages": 3, "pagination_parallelism": 2, "pagination_partial_results": true }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		field, _ := res.Frames[0].FieldByName("page")
+		require.NotNil(t, field)
+		first, _ := field.ConcreteAt(0)
+		last, _ := field.ConcreteAt(1)
+		require.Equal(t, "1", first)
+		require.Equal(t, "3", last)
+		require.Equal(t, 1, len(res.Frames[0].Meta.Notices))
+		assert.Equal(t, data.NoticeSeverityWarning, res.Frames[0].Meta.Notices[0].Severity)
+		assert.Contains(t, res.Frames[0].Meta.Notices[0].Text, "Page 2 failed with status 500")
+		require.Equal(t, 2, len(res.Frames[0].Meta.Stats))
+		assert.Equal(t, "Pages fetched", res.Frames[0].Meta.Stats[0].DisplayName)
+		assert.Equal(t, float64(2), res.Frames[0].Meta.Stats[0].Value)
+		assert.Equal(t, "Pages failed", res.Frames[0].Meta.Stats[1].DisplayName)
+		assert.Equal(t, float64(1), res.Frames[0].Meta.Stats[1].Value)
+	})
+	t.Run("should return the pages before the failed cursor page", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/cursor", "root_selector": "items", "pagination_mode": "cursor", "pagination_max_pages": 3, "pagination_param_cursor_field_name": "page", "pagination_param_cursor_extraction_path": "next", "pagination_partial_results": true }`, server.URL)),
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 1, res.Frames[0].Rows())
+		require.Equal(t, 1, len(res.Frames[0].Meta.Notices))
+		assert.Contains(t, res.Frames[0].Meta.Notices[0].Text, "Page 2 failed with status 500 and the next pages are not requested")
+	})
+	t.Run("should fail the query when all the pages fail", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/all", "pagination_mode": "page", "pagination_max_pages": 2, "pagination_partial_results": true }`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "all the pages failed")
+	})
+}
@@ -0,0 +1,115 @@
+package infinity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+// applyProxy configures the proxy of the transport based on the proxy type, credentials, proxy CA certificate and the no proxy rules of the settings
+func applyProxy(transport *http.Transport, settings models.InfinitySettings) error {
+	noProxyRules, err := models.GetNoProxyRules(settings.NoProxy)
+	if err != nil {
+		return err
+	}
+	switch settings.ProxyType {
+	case models.ProxyTypeNone:
+		backend.Logger.Debug("proxy type is set to none. Not using the proxy")
+		return nil
+	case models.ProxyTypeUrl:
+		backend.Logger.Debug("proxy type is set to url. Using the proxy", "proxy_url", settings.ProxyUrl)
+		proxyURL, err := getProxyURL(settings)
+		if err != nil {
+			return err
+		}
+		transport.Proxy = withNoProxyRules(http.ProxyURL(proxyURL), noProxyRules)
+		if proxyURL.Scheme == "https" && settings.ProxyCACert != "" {
+			return applyProxyCACert(transport, proxyURL, settings.ProxyCACert)
+		}
+		return nil
+	default:
+		transport.Proxy = withNoProxyRules(http.ProxyFromEnvironment, noProxyRules)
+		return nil
+	}
+}
+
+// getProxyURL returns the proxy url with the credentials from the settings. Credentials of the settings take precedence over the credentials embedded in the url
+func getProxyURL(settings models.InfinitySettings) (*url.URL, error) {
+	proxyURL, err := models.GetProxyURL(settings.ProxyUrl)
+	if err != nil {
+		return nil, err
+	}
+	if settings.ProxyUsername != "" {
+		proxyURL.User = url.UserPassword(settings.ProxyUsername, settings.ProxyPassword)
+	}
+	return proxyURL, nil
+}
+
+// withNoProxyRules bypasses the proxy for the hosts matching the no proxy rules and for the unix sockets
+func withNoProxyRules(proxy func(*http.Request) (*url.URL, error), noProxyRules []models.NoProxyRule) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if isUnixSocketRequest(req) {
+			return nil, nil
+		}
+		for _, rule := range noProxyRules {
+			if rule.Match(req.URL.Host) {
+				return nil, nil
+			}
+		}
+		return proxy(req)
+	}
+}
+
+/*
+ * applyProxyCACert verifies the https proxy using the proxy CA certificate.
+ *
+ * The transport uses the same tls config for the proxy and the target servers. So the tls connections are dialed here
+ * so that the proxy CA certificate is trusted only for the proxy and not for the target servers.
+ */
+func applyProxyCACert(transport *http.Transport, proxyURL *url.URL, proxyCACert string) error {
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM([]byte(proxyCACert)) {
+		return errors.New("invalid proxy CA certificate")
+	}
+	proxyAddr := canonicalAddr(proxyURL)
+	dialContext := transport.DialContext
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig := &tls.Config{RootCAs: caPool, ServerName: host}
+		if addr != proxyAddr {
+			tlsConfig = transport.TLSClientConfig.Clone()
+			if tlsConfig.ServerName == "" {
+				tlsConfig.ServerName = host
+			}
+		}
+		conn, err := dialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+	return nil
+}
+
+func canonicalAddr(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,151 @@
+package infinity
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+// rateLimiter enforces the rate limit and the concurrency cap of the requests to the urls starting with the host
+type rateLimiter struct {
+	host   string
+	bucket *tokenBucket
+	slots  chan struct{}
+}
+
+func getRateLimiters(rateLimits []models.RateLimit) []*rateLimiter {
+	var limiters []*rateLimiter
+	for _, rateLimit := range rateLimits {
+		limiter := &rateLimiter{host: strings.TrimSpace(rateLimit.Host)}
+		if rateLimit.RequestsPerSecond > 0 {
+			limiter.bucket = newTokenBucket(rateLimit.RequestsPerSecond, rateLimit.Burst)
+		}
+		if rateLimit.MaxConcurrency > 0 {
+			limiter.slots = make(chan struct{}, rateLimit.MaxConcurrency)
+		}
+		limiters = append(limiters, limiter)
+	}
+	return limiters
+}
+
+// acquireRateLimit waits for the rate limit and the concurrency slot of the first limiter matching the url.
+// The returned release func must be called once the request is completed
+func (client *Client) acquireRateLimit(ctx context.Context, url string) (release func(), err error) {
+	for _, limiter := range client.rateLimiters {
+		if strings.HasPrefix(url, limiter.host) {
+			return limiter.acquire(ctx)
+		}
+	}
+	return func() {}, nil
+}
+
+func (limiter *rateLimiter) acquire(ctx context.Context) (release func(), err error) {
+	startTime := time.Now()
+	defer func() { addRateLimitWait(ctx, time.Since(startTime)) }()
+	release = func() {}
+	if limiter.slots != nil {
+		select {
+		case limiter.slots <- struct{}{}:
+			release = func() { <-limiter.slots }
+		case <-ctx.Done():
+			return release, fmt.Errorf("request cancelled while waiting for the concurrency limit of %s. %w", limiter.host, ctx.Err())
+		}
+	}
+	if limiter.bucket != nil {
+		if err := limiter.bucket.wait(ctx); err != nil {
+			release()
+			return func() {}, fmt.Errorf("request cancelled while waiting for the rate limit of %s. %w", limiter.host, err)
+		}
+	}
+	return release, nil
+}
+
+// tokenBucket is a token bucket rate limiter. Tokens are reserved in advance, so the waiting requests are served in order
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// wait reserves a token and waits until it is available. The token is returned when the context is done before that
+func (b *tokenBucket) wait(ctx context.Context) error {
+	now := time.Now()
+	delay := b.reserve(now)
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		b.cancel()
+		return fmt.Errorf("rate limit wait of %s exceeds the query deadline", delay.Round(time.Millisecond))
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+type rateLimitWaitKey struct{}
+
+type rateLimitWait struct {
+	mu       sync.Mutex
+	duration time.Duration
+}
+
+// withRateLimitWait returns the context which accumulates the rate limit wait time of all the requests of the query
+func withRateLimitWait(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rateLimitWaitKey{}, &rateLimitWait{})
+}
+
+func addRateLimitWait(ctx context.Context, duration time.Duration) {
+	if wait, ok := ctx.Value(rateLimitWaitKey{}).(*rateLimitWait); ok {
+		wait.mu.Lock()
+		defer wait.mu.Unlock()
+		wait.duration += duration
+	}
+}
+
+func getRateLimitWait(ctx context.Context) time.Duration {
+	if wait, ok := ctx.Value(rateLimitWaitKey{}).(*rateLimitWait); ok {
+		wait.mu.Lock()
+		defer wait.mu.Unlock()
+		return wait.duration
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package infinity_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestRateLimits(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		_, _ = io.WriteString(w, `[{"id":1}]`)
+	})
+	t.Run("should wait for the rate limit across the pages and report the wait time", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{
+			RateLimits: []models.RateLimit{{Host: server.URL, RequestsPerSecond: 10, Burst: 1}},
+		})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/foo", "pagination_mode": "page", "pagination_max_pages": 3 }`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		require.Equal(t, 3, res.Frames[0].Rows())
+		metaData := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+		require.GreaterOrEqual(t, metaData.RateLimitWait, 50*time.Millisecond)
+	})
+	t.Run("should limit the in-flight requests across the queries", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{
+			RateLimits: []models.RateLimit{{Host: server.URL, MaxConcurrency: 1}},
+		})
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res := queryData(context.Background(), backend.DataQuery{
+					JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "url": "%s/foo" }`, server.URL)),
+				}, *client, map[string]string{})
+				assert.Nil(t, res.Error)
+			}()
+		}
+		wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 1, maxInFlight)
+	})
+	t.Run("should not wait beyond the query deadline", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{
+			RateLimits: []models.RateLimit{{Host: server.URL, RequestsPerSecond: 0.1}},
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		for i, wantErr := range []bool{false, true} {
+			startTime := time.Now()
+			res := queryData(ctx, backend.DataQuery{
+				JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "url": "%s/foo?i=%d" }`, server.URL, i)),
+			}, *client, map[string]string{})
+			require.NotNil(t, res)
+			if !wantErr {
+				require.Nil(t, res.Error)
+				continue
+			}
+			require.NotNil(t, res.Error)
+			assert.Contains(t, res.Error.Error(), "exceeds the query deadline")
+			assert.Less(t, time.Since(startTime), 500*time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package infinity
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+var xmlDeclarationRegex = regexp.MustCompile(`^\s*<\?xml[^>]*\?>`)
+
+// isRawPagination returns true for the uql and groq queries. Their pages are merged as the raw responses, as the uql and groq queries are evaluated by the frontend
+func isRawPagination(query models.Query) bool {
+	return models.IsUQLOrGROQQuery(query)
+}
+
+// getPageRows returns the number of the rows of the page. The rows of the raw pages are the items of the json arrays or the records of the csv responses
+func getPageRows(query models.Query, frame *data.Frame) int {
+	if frame == nil {
+		return 0
+	}
+	if !isRawPagination(query) {
+		return frame.Rows()
+	}
+	switch responseObject := getResponseObject(frame).(type) {
+	case nil:
+		return 0
+	case []any:
+		return len(responseObject)
+	case string:
+		if strings.TrimSpace(responseObject) == "" {
+			return 0
+		}
+		if !isXMLResponse(query, responseObject) {
+			if _, records, err := readCSVRecords(query, responseObject); err == nil {
+				return len(records)
+			}
+		}
+		return 1
+	default:
+		return 1
+	}
+}
+
+/*
+ * mergeRawPages merges the raw responses of the pages of the uql and groq queries into a single frame, so that the uql or groq query is evaluated once on all the pages.
+ *
+ *		json arrays		items of the pages are concatenated
+ *		csv, tsv		records of the pages are concatenated. The header row is kept from the first page only
+ *		xml				documents of the pages are wrapped in a `pages` root element, for example `pages.feed.entry` of the pages with the feed root element
+ *		others			pages are merged as an array of the page responses, for example the json objects of the pages
+ *
+ * The total rows limit of the query applies to the json arrays and the csv records
+ */
+func mergeRawPages(query models.Query, frames []*data.Frame) *data.Frame {
+	frame := GetDummyFrame(query)
+	customMeta := &CustomMeta{Query: query}
+	pages := []any{}
+	for _, pageFrame := range frames {
+		if pageFrame == nil {
+			continue
+		}
+		if pageFrame.Meta != nil {
+			if pageMeta, ok := pageFrame.Meta.Custom.(*CustomMeta); ok {
+				if customMeta.ResponseCodeFromServer == 0 {
+					customMeta.ResponseCodeFromServer = pageMeta.ResponseCodeFromServer
+				}
+				customMeta.Duration += pageMeta.Duration
+			}
+			if len(pages) == 0 {
+				frame.Meta.ExecutedQueryString = pageFrame.Meta.ExecutedQueryString
+			}
+		}
+		pages = append(pages, getResponseObject(pageFrame))
+	}
+	customMeta.Data = mergeRawResponses(query, pages)
+	frame.Meta.Custom = customMeta
+	return frame
+}
+
+func mergeRawResponses(query models.Query, pages []any) any {
+	if len(pages) == 0 {
+		return nil
+	}
+	if items, ok := mergeJSONArrays(pages); ok {
+		if query.PageMaxRows > 0 && len(items) > query.PageMaxRows {
+			items = items[:query.PageMaxRows]
+		}
+		return items
+	}
+	if responseStrings, ok := getResponseStrings(pages); ok {
+		if isXMLResponse(query, responseStrings[0]) {
+			return mergeXMLResponses(responseStrings)
+		}
+		return mergeCSVResponses(query, responseStrings)
+	}
+	return pages
+}
+
+func mergeJSONArrays(pages []any) ([]any, bool) {
+	items := []any{}
+	for _, page := range pages {
+		pageItems, ok := page.([]any)
+		if !ok {
+			return nil, false
+		}
+		items = append(items, pageItems...)
+	}
+	return items, true
+}
+
+func getResponseStrings(pages []any) ([]string, bool) {
+	responseStrings := []string{}
+	for _, page := range pages {
+		responseString, ok := page.(string)
+		if !ok {
+			return nil, false
+		}
+		responseStrings = append(responseStrings, responseString)
+	}
+	return responseStrings, true
+}
+
+// isXMLResponse returns true for the xml queries. The uql and groq query types have no response type, so the xml responses are detected by the content
+func isXMLResponse(query models.Query, responseString string) bool {
+	switch query.Type {
+	case models.QueryTypeXML, models.QueryTypeHTML:
+		return true
+	case models.QueryTypeCSV, models.QueryTypeTSV:
+		return false
+	default:
+		return strings.HasPrefix(strings.TrimSpace(responseString), "<")
+	}
+}
+
+func mergeXMLResponses(responseStrings []string) string {
+	var sb strings.Builder
+	sb.WriteString("<pages>")
+	for _, responseString := range responseStrings {
+		sb.WriteString(strings.TrimSpace(xmlDeclarationRegex.ReplaceAllString(responseString, "")))
+	}
+	sb.WriteString("</pages>")
+	return sb.String()
+}
+
+// mergeCSVResponses concatenates the records of the csv pages. The first line of the pages after the first page is the repeated header row, unless the columns of the csv options are the header
+func mergeCSVResponses(query models.Query, responseStrings []string) string {
+	hasHeader := query.CSVOptions.Columns == ""
+	lines := []string{}
+	rows := 0
+	for pageIndex, responseString := range responseStrings {
+		for lineIndex, line := range strings.Split(strings.TrimRight(responseString, "\r\n"), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if hasHeader && lineIndex == 0 {
+				if pageIndex == 0 {
+					lines = append(lines, line)
+				}
+				continue
+			}
+			if query.PageMaxRows > 0 && rows >= query.PageMaxRows {
+				break
+			}
+			lines = append(lines, line)
+			rows++
+		}
+	}
+	return strings.Join(lines, "\n")
+}
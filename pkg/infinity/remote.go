@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
@@ -18,10 +19,29 @@ import (
 func GetFrameForURLSources(ctx context.Context, query models.Query, infClient Client, requestHeaders map[string]string) (*data.Frame, error) {
 	ctx, span := tracing.DefaultTracer().Start(ctx, "GetFrameForURLSources")
 	defer span.End()
-	if query.Type == models.QueryTypeJSON && query.Parser == models.InfinityParserBackend && query.PageMode != models.PaginationModeNone && query.PageMode != "" {
-		return GetPaginatedResults(ctx, query, infClient, requestHeaders)
+	ctx = withRateLimitWait(ctx)
+	var frame *data.Frame
+	var err error
+	if canPaginate(query) {
+		frame, err = GetPaginatedResults(ctx, query, infClient, requestHeaders)
+	} else {
+		frame, _, err = GetFrameForURLSourcesWithPostProcessing(ctx, query, infClient, requestHeaders, true)
+	}
+	if wait := getRateLimitWait(ctx); wait > 0 && frame != nil && frame.Meta != nil {
+		if customMeta, ok := frame.Meta.Custom.(*CustomMeta); ok {
+			customMeta.RateLimitWait = wait
+		}
+	}
+	if infClient.staleFrames != nil {
+		if err == nil {
+			infClient.staleFrames.store(query, frame, time.Now())
+		} else if !canServeStale(err) {
+			return frame, err
+		} else if staleFrame, ok := infClient.staleFrames.get(query, time.Duration(infClient.Settings.StaleMaxAgeInSeconds)*time.Second, err, time.Now()); ok {
+			backend.Logger.Warn("serving the last successful response as the upstream request failed", "error", err.Error())
+			return staleFrame, nil
+		}
 	}
-	frame, _, err := GetFrameForURLSourcesWithPostProcessing(ctx, query, infClient, requestHeaders, true)
 	return frame, err
 }
 
@@ -31,13 +51,15 @@ func GetPaginatedResults(ctx context.Context, query models.Query, infClient Clie
 	frames := []*data.Frame{}
 	queries := []models.Query{}
 	var errs error
+	query.PageMaxPages = getPaginationMaxPages(infClient.Settings, query)
+	pagination := &paginationState{query: query}
 	switch query.PageMode {
 	case models.PaginationModeOffset:
 		for pageNumber := 1; pageNumber <= query.PageMaxPages; pageNumber++ {
 			offset := query.PageParamOffsetFieldVal + ((pageNumber - 1) * query.PageParamSizeFieldVal)
 			currentQuery := query
-			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamSizeFieldType, query.PageParamSizeFieldName, fmt.Sprintf("%d", query.PageParamSizeFieldVal))
-			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamOffsetFieldType, query.PageParamOffsetFieldName, fmt.Sprintf("%d", offset))
+			currentQuery = applyPaginationItemToQuery(currentQuery, query.PageParamSizeFieldType, query.PageParamSizeFieldName, fmt.Sprintf("%d", query.PageParamSizeFieldVal), paginationValueNumber)
+			currentQuery = applyPaginationItemToQuery(currentQuery, query.PageParamOffsetFieldType, query.PageParamOffsetFieldName, fmt.Sprintf("%d", offset), paginationValueNumber)
 			queries = append(queries, currentQuery)
 		}
 	case models.PaginationModePage:
@@ -47,8 +69,8 @@ func GetPaginatedResults(ctx context.Context, query models.Query, infClient Clie
 		}
 		for pageNumber := 0; pageNumber < query.PageMaxPages; pageNumber++ {
 			currentQuery := query
-			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamSizeFieldType, query.PageParamSizeFieldName, fmt.Sprintf("%d", query.PageParamSizeFieldVal))
-			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamPageFieldType, query.PageParamPageFieldName, fmt.Sprintf("%d", initialPageNumber+pageNumber))
+			currentQuery = applyPaginationItemToQuery(currentQuery, query.PageParamSizeFieldType, query.PageParamSizeFieldName, fmt.Sprintf("%d", query.PageParamSizeFieldVal), paginationValueNumber)
+			currentQuery = applyPaginationItemToQuery(currentQuery, query.PageParamPageFieldType, query.PageParamPageFieldName, fmt.Sprintf("%d", initialPageNumber+pageNumber), paginationValueNumber)
 			queries = append(queries, currentQuery)
 		}
 	case models.PaginationModeList:
@@ -61,22 +83,41 @@ func GetPaginatedResults(ctx context.Context, query models.Query, infClient Clie
 			currentQuery = ApplyPaginationItemToQuery(currentQuery, query.PageParamListFieldType, query.PageParamListFieldName, strings.TrimSpace(listItem))
 			queries = append(queries, currentQuery)
 		}
-	case models.PaginationModeCursor:
+	case models.PaginationModeTimeWindow:
+		windows, err := getTimeWindows(query, query.PageMaxPages)
+		if err != nil {
+			return nil, err
+		}
+		// epoch time windows are numbers while the rfc3339 time windows are strings
+		windowValueType := paginationValueNumber
+		if query.PageTimeWindowFormat == models.TimeWindowFormatRFC3339 {
+			windowValueType = paginationValueString
+		}
+		for _, window := range windows {
+			currentQuery := query
+			currentQuery = applyPaginationItemToQuery(currentQuery, query.PageParamStartFieldType, query.PageParamStartFieldName, formatTimeWindowValue(window.From, query.PageTimeWindowFormat), windowValueType)
+			currentQuery = applyPaginationItemToQuery(currentQuery, query.PageParamEndFieldType, query.PageParamEndFieldName, formatTimeWindowValue(window.To, query.PageTimeWindowFormat), windowValueType)
+			queries = append(queries, currentQuery)
+		}
+	case models.PaginationModeCursor, models.PaginationModeNextLink, models.PaginationModeRelay:
 		queries = append(queries, query)
 	default:
 		frame, _, err := GetFrameForURLSourcesWithPostProcessing(ctx, query, infClient, requestHeaders, true)
 		return frame, err
 	}
-	if query.PageMode != models.PaginationModeCursor {
-		for _, currentQuery := range queries {
-			frame, _, err := GetFrameForURLSourcesWithPostProcessing(ctx, currentQuery, infClient, requestHeaders, false)
-			frames = append(frames, frame)
-			errs = errors.Join(errs, err)
+	if query.PageMode != models.PaginationModeCursor && query.PageMode != models.PaginationModeNextLink && query.PageMode != models.PaginationModeRelay {
+		pageFrames, err := getPageFrames(ctx, queries, infClient, requestHeaders, pagination)
+		if query.PageMode == models.PaginationModeTimeWindow && !isRawPagination(query) {
+			// windows are requested oldest first, so the merged frame is in the time order
+			pageFrames = withoutEmptyFrames(pageFrames)
 		}
+		frames = append(frames, pageFrames...)
+		errs = errors.Join(errs, err)
 	}
 	if query.PageMode == models.PaginationModeCursor {
 		i := 0
 		oCursor := ""
+		cursors := map[string]bool{}
 		for {
 			currentQuery := query
 			if i > 0 && oCursor != "" {
@@ -87,22 +128,106 @@ func GetPaginatedResults(ctx context.Context, query models.Query, infClient Clie
 			}
 			i++
 			frame, cursor, err := GetFrameForURLSourcesWithPostProcessing(ctx, currentQuery, infClient, requestHeaders, false)
+			if err != nil {
+				errs = errors.Join(errs, pagination.failed(i-1, frame, err))
+				break
+			}
 			oCursor = cursor
 			frames = append(frames, frame)
-			errs = errors.Join(errs, err)
+			if _, next := pagination.next(frame, i-1); !next {
+				break
+			}
+			if cursors[cursor] {
+				// the same cursor would request the pages already fetched again and again
+				backend.Logger.Warn("stopping the cursor pagination as the cursor is repeated", "cursor", cursor)
+				break
+			}
+			cursors[cursor] = true
+		}
+	}
+	if query.PageMode == models.PaginationModeNextLink {
+		currentQuery := query
+		for pageNumber := 1; pageNumber <= query.PageMaxPages; pageNumber++ {
+			frame, nextLink, err := GetFrameForURLSourcesWithPostProcessing(ctx, currentQuery, infClient, requestHeaders, false)
+			if err != nil {
+				errs = errors.Join(errs, pagination.failed(pageNumber-1, frame, err))
+				break
+			}
+			frames = append(frames, frame)
+			if nextLink == "" {
+				break
+			}
+			if _, next := pagination.next(frame, pageNumber-1); !next {
+				break
+			}
+			nextURL, err := getNextLinkURL(infClient.Settings, currentQuery.URL, nextLink)
+			if err != nil {
+				errs = errors.Join(errs, err)
+				break
+			}
+			// the next link already contains the query params of the next page
+			currentQuery.URL = nextURL
+			currentQuery.URLOptions.Params = nil
+		}
+	}
+	if query.PageMode == models.PaginationModeRelay {
+		currentQuery := query
+		currentQuery.RootSelector = getRelayNodesSelector(query.RootSelector)
+		endCursors := map[string]bool{}
+		for pageIndex := 0; pageIndex < query.PageMaxPages; pageIndex++ {
+			frame, endCursor, err := GetFrameForURLSourcesWithPostProcessing(ctx, currentQuery, infClient, requestHeaders, false)
+			if err != nil {
+				errs = errors.Join(errs, pagination.failed(pageIndex, frame, err))
+				break
+			}
+			include, next := pagination.next(frame, pageIndex)
+			if include && (getPageRows(query, frame) > 0 || pageIndex == 0) {
+				frames = append(frames, frame)
+			}
+			hasNextPage, err := getResponseValue(query, getResponseObject(frame), query.PageParamHasMoreExtractionPath)
+			if !next || err != nil || hasNextPage != "true" || endCursor == "" {
+				break
+			}
+			if endCursors[endCursor] {
+				backend.Logger.Warn("stopping the relay pagination as the end cursor is repeated", "cursor", endCursor)
+				break
+			}
+			endCursors[endCursor] = true
+			if currentQuery, err = setGraphQLVariable(currentQuery, query.PageParamCursorFieldName, endCursor); err != nil {
+				errs = errors.Join(errs, err)
+				break
+			}
 		}
 	}
 	if errs != nil {
 		return nil, errs
 	}
+	if len(frames) == 0 && len(pagination.failures) > 0 {
+		for _, failure := range pagination.failures {
+			errs = errors.Join(errs, failure.err)
+		}
+		return nil, fmt.Errorf("all the pages failed. %w", errs)
+	}
+	if isRawPagination(query) {
+		return pagination.applyMeta(mergeRawPages(query, frames)), nil
+	}
 	mergedFrame, err := transformations.Merge(frames, transformations.MergeFramesOptions{})
 	if err != nil {
 		return nil, err
 	}
-	return PostProcessFrame(ctx, mergedFrame, query)
+	frame, err := PostProcessFrame(ctx, limitFrameRows(mergedFrame, query.PageMaxRows), query)
+	if err != nil {
+		return frame, err
+	}
+	return pagination.applyMeta(frame), nil
 }
 
 func ApplyPaginationItemToQuery(currentQuery models.Query, fieldType models.PaginationParamType, fieldName string, fieldValue string) models.Query {
+	return applyPaginationItemToQuery(currentQuery, fieldType, fieldName, fieldValue, paginationValueString)
+}
+
+// applyPaginationItemToQuery applies the pagination value to the query. The value type decides the JSON type of the value set in the json body
+func applyPaginationItemToQuery(currentQuery models.Query, fieldType models.PaginationParamType, fieldName string, fieldValue string, valueType paginationValueType) models.Query {
 	if strings.TrimSpace(fieldValue) == "" {
 		return currentQuery
 	}
@@ -112,6 +237,13 @@ func ApplyPaginationItemToQuery(currentQuery models.Query, fieldType models.Pagi
 		currentQuery.URLOptions.Headers = append(currentQuery.URLOptions.Headers, field)
 	case models.PaginationParamTypeBodyData:
 		currentQuery.URLOptions.BodyForm = append(currentQuery.URLOptions.BodyForm, field)
+	case models.PaginationParamTypeBodyJson:
+		query, err := setJSONBodyValue(currentQuery, fieldName, fieldValue, valueType)
+		if err != nil {
+			backend.Logger.Error("error setting the pagination value in the json body", "field", fieldName, "error", err.Error())
+			return currentQuery
+		}
+		currentQuery = query
 	case models.PaginationParamTypeReplace:
 		currentQuery.URL = strings.ReplaceAll(currentQuery.URL, fieldName, field.Value)
 		currentQuery.URLOptions.Body = strings.ReplaceAll(currentQuery.URLOptions.Body, fieldName, field.Value)
@@ -139,6 +271,7 @@ func GetFrameForURLSourcesWithPostProcessing(ctx context.Context, query models.Q
 	defer span.End()
 	frame := GetDummyFrame(query)
 	cursor := ""
+	ctx, responseHeaders := withResponseHeaders(ctx)
 	urlResponseObject, statusCode, duration, err := infClient.GetResults(ctx, query, requestHeaders)
 	frame.Meta.ExecutedQueryString = infClient.GetExecutedURL(ctx, query)
 	if infClient.IsMock {
@@ -154,7 +287,7 @@ func GetFrameForURLSourcesWithPostProcessing(ctx context.Context, query models.Q
 			Query:                  query,
 			Error:                  err.Error(),
 		}
-		return frame, cursor, err
+		return frame, cursor, &upstreamError{statusCode: statusCode, err: err}
 	}
 	if query.Type == models.QueryTypeGSheets {
 		if frame, err = GetGoogleSheetsResponse(urlResponseObject, query); err != nil {
@@ -226,15 +359,20 @@ func GetFrameForURLSourcesWithPostProcessing(ctx context.Context, query models.Q
 		}
 		return frame, cursor, err
 	}
-	if query.PageMode == models.PaginationModeCursor && strings.TrimSpace(query.PageParamCursorFieldExtractionPath) != "" {
-		body, err := json.Marshal(urlResponseObject)
-		if err != nil {
-			return frame, cursor, errors.New("error while finding the cursor value")
-		}
-		cursor, err = jsonframer.GetRootData(string(body), query.PageParamCursorFieldExtractionPath)
+	if query.PageMode == models.PaginationModeCursor && query.PageParamCursorExtractionSource == models.PaginationCursorSourceHeader {
+		// continuation tokens such as x-ms-continuation or X-Next-Token
+		return frame, strings.TrimSpace(responseHeaders.header.Get(query.PageParamCursorFieldExtractionPath)), nil
+	}
+	if (query.PageMode == models.PaginationModeCursor || query.PageMode == models.PaginationModeRelay) && strings.TrimSpace(query.PageParamCursorFieldExtractionPath) != "" {
+		cursor, err = getResponseValue(query, urlResponseObject, query.PageParamCursorFieldExtractionPath)
 		if err != nil {
 			return frame, cursor, errors.New("error while extracting the cursor value")
 		}
 	}
+	if query.PageMode == models.PaginationModeNextLink {
+		if cursor, err = getNextLink(query, urlResponseObject, responseHeaders.header); err != nil {
+			return frame, cursor, err
+		}
+	}
 	return frame, cursor, nil
 }
@@ -20,11 +20,16 @@ func GetRequest(ctx context.Context, settings models.InfinitySettings, body io.R
 	if err != nil {
 		return nil, err
 	}
+	if _, _, ok := splitUnixSocketURL(url); ok {
+		if url, err = getUnixSocketRequestURL(url); err != nil {
+			return nil, err
+		}
+	}
 	switch strings.ToUpper(query.URLOptions.Method) {
 	case http.MethodPost:
-		req, err = http.NewRequest(http.MethodPost, url, body)
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	default:
-		req, err = http.NewRequest(http.MethodGet, url, nil)
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	}
 	req = ApplyAcceptHeader(query, settings, req, includeSect)
 	req = ApplyContentTypeHeader(query, settings, req, includeSect)
@@ -34,6 +39,7 @@ func GetRequest(ctx context.Context, settings models.InfinitySettings, body io.R
 	req = ApplyBearerToken(settings, req, includeSect)
 	req = ApplyApiKeyAuth(settings, req, includeSect)
 	req = ApplyForwardedOAuthIdentity(requestHeaders, settings, req, includeSect)
+	req = ApplyHMACAuth(settings, req, includeSect)
 	return req, err
 }
 
@@ -91,16 +97,28 @@ func NormalizeURL(u string) string {
 func (client *Client) GetExecutedURL(ctx context.Context, query models.Query) string {
 	out := []string{}
 	if query.Source != "inline" && query.Source != "azure-blob" {
+		client = client.getClientForQuery(ctx, query)
 		req, err := GetRequest(ctx, client.Settings, GetQueryBody(query), query, map[string]string{}, false)
 		if err != nil {
 			return fmt.Sprintf("error retrieving full url. %s", query.URL)
 		}
+		executedURL := req.URL.String()
+		curlArgs := ""
+		if socketURL, ok := getUnixSocketURL(executedURL); ok {
+			socketPath, _ := getUnixSocketPath(req.URL.Host)
+			executedURL, curlArgs = socketURL, fmt.Sprintf(" --unix-socket '%s'", socketPath)
+			req.URL.Host = "localhost"
+		}
 		command, err := http2curl.GetCurlCommand(req)
 		if err != nil {
 			return fmt.Sprintf("error retrieving full url. %s", query.URL)
 		}
-		out = append(out, "###############", "## URL", "###############", "", req.URL.String(), "")
-		out = append(out, "###############", "## Curl Command", "###############", "", command.String())
+		out = append(out, "###############", "## URL", "###############", "", executedURL, "")
+		curlCommand := command.String() + curlArgs
+		if dnsArgs := getCurlDNSArgs(client.Settings, req.URL); dnsArgs != "" {
+			curlCommand = curlCommand + " " + dnsArgs
+		}
+		out = append(out, "###############", "## Curl Command", "###############", "", curlCommand)
 	}
 	if query.Type == models.QueryTypeUQL || query.Parser == "uql" {
 		out = append(out, "", "###############", "## UQL", "###############", "", query.UQL)
@@ -117,5 +135,8 @@ func (client *Client) GetExecutedURL(ctx context.Context, query models.Query) st
 	if client.Settings.AuthenticationMethod == models.AuthenticationMethodAzureBlob {
 		out = append(out, "###############", "> Authentication steps not included for azure blob authentication")
 	}
+	if client.Settings.AuthenticationMethod == models.AuthenticationMethodAzureAD {
+		out = append(out, "###############", "> Authentication steps not included for azure ad authentication")
+	}
 	return strings.Join(out, "\n")
 }
@@ -195,7 +195,7 @@ func TestClient_GetExecutedURL(t *testing.T) {
 			settings: models.InfinitySettings{AuthenticationMethod: "zcap"},
 			query:    models.Query{URL: "https://foo.com"},
 			url:      "https://foo.com",
-			command:  "curl -X 'GET' -H 'Key: xxxxxxxx' 'https://foo.com'",
+			command:  "curl -X 'GET' 'https://foo.com'",
 		},
 
 		{
@@ -216,6 +216,12 @@ func TestClient_GetExecutedURL(t *testing.T) {
 			url:      "https://foo.com?me=xxxxxxxx&something=xxxxxxxx",
 			command:  "curl -X 'POST' -d 'my request body with ${__qs.me} value' -H 'Accept: application/json;q=0.9,text/plain' -H 'Content-Type: application/json' -H 'Good: xxxxxxxx' -H 'Hello: xxxxxxxx' 'https://foo.com?me=xxxxxxxx&something=xxxxxxxx'",
 		},
+		{
+			settings: models.InfinitySettings{URL: "unix:///var/run/docker.sock:"},
+			query:    models.Query{URL: "/containers/json", URLOptions: models.URLOptions{Params: []models.URLOptionKeyValuePair{{Key: "all", Value: "true"}}}},
+			url:      "unix:///var/run/docker.sock:/containers/json?all=true",
+			command:  "curl -X 'GET' 'http://localhost/containers/json?all=true' --unix-socket '/var/run/docker.sock'",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -226,6 +232,41 @@ func TestClient_GetExecutedURL(t *testing.T) {
 	}
 }
 
+func TestClient_GetExecutedURLWithDNSSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings models.InfinitySettings
+		url      string
+		command  string
+	}{
+		{
+			name:     "should include the matching dns override",
+			settings: models.InfinitySettings{DNSOverrides: []models.DNSOverride{{Host: "bar.com", IP: "10.0.0.1"}, {Host: "foo.com", IP: "10.0.0.2"}}},
+			url:      "https://foo.com/hello",
+			command:  "curl -X 'GET' 'https://foo.com/hello' --resolve 'foo.com:443:10.0.0.2'",
+		},
+		{
+			name:     "should not include the dns override of other port",
+			settings: models.InfinitySettings{DNSOverrides: []models.DNSOverride{{Host: "foo.com", Port: "8080", IP: "10.0.0.2"}}},
+			url:      "http://foo.com/hello",
+			command:  "curl -X 'GET' 'http://foo.com/hello'",
+		},
+		{
+			name:     "should include the ipv6 dns override and dns server",
+			settings: models.InfinitySettings{DNSOverrides: []models.DNSOverride{{Host: "foo.com", Port: "8080", IP: "fd00::1"}}, DNSServer: "10.0.0.53"},
+			url:      "http://foo.com:8080/hello",
+			command:  "curl -X 'GET' 'http://foo.com:8080/hello' --resolve 'foo.com:8080:[fd00::1]' --dns-servers '10.0.0.53:53'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &infinity.Client{Settings: tt.settings}
+			got := client.GetExecutedURL(context.TODO(), models.Query{URL: tt.url})
+			assert.Equal(t, fmt.Sprintf("###############\n## URL\n###############\n\n%s\n\n###############\n## Curl Command\n###############\n\n%s", tt.url, tt.command), got)
+		})
+	}
+}
+
 func TestNormalizeURL(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,126 @@
+package infinity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+// maximum number of the last good responses kept per datasource instance
+const staleFramesMaxSize = 500
+
+// staleFrames keeps the last successful frame of each query so that it can be served when the upstream errors
+type staleFrames struct {
+	mu     sync.Mutex
+	frames map[string]staleFrame
+}
+
+type staleFrame struct {
+	frame     *data.Frame
+	createdAt time.Time
+}
+
+func newStaleFrames() *staleFrames {
+	return &staleFrames{frames: map[string]staleFrame{}}
+}
+
+// upstreamError is the error of the upstream request along with its status code, so that the stale responses are served only for the failures of the upstream
+type upstreamError struct {
+	statusCode int
+	err        error
+}
+
+func (e *upstreamError) Error() string {
+	return e.err.Error()
+}
+
+func (e *upstreamError) Unwrap() error {
+	return e.err
+}
+
+// canServeStale returns true for the transport errors, the server errors and the open circuits. Client errors such as 401 and 404,
+// the urls which are not allowed and the responses which can't be parsed are returned as they are
+func canServeStale(err error) bool {
+	var upstreamErr *upstreamError
+	if !errors.As(err, &upstreamErr) {
+		return false
+	}
+	return upstreamErr.statusCode == 0 || upstreamErr.statusCode >= http.StatusInternalServerError
+}
+
+// getStaleFrameKey returns the key of the query. The time range is not part of the query json, so it is added to the key of the
+// time window queries which get their windows from the time range instead of the macros of the url
+func getStaleFrameKey(query models.Query) (string, bool) {
+	b, err := json.Marshal(query)
+	if err != nil {
+		return "", false
+	}
+	if query.PageMode == models.PaginationModeTimeWindow {
+		b = fmt.Appendf(b, "|%d|%d", query.TimeRange.From.UnixNano(), query.TimeRange.To.UnixNano())
+	}
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:]), true
+}
+
+func (s *staleFrames) store(query models.Query, frame *data.Frame, now time.Time) {
+	key, ok := getStaleFrameKey(query)
+	if !ok || frame == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.frames[key]; !exists && len(s.frames) >= staleFramesMaxSize {
+		oldestKey := ""
+		for k, v := range s.frames {
+			if oldestKey == "" || v.createdAt.Before(s.frames[oldestKey].createdAt) {
+				oldestKey = k
+			}
+		}
+		delete(s.frames, oldestKey)
+	}
+	s.frames[key] = staleFrame{frame: copyFrame(frame), createdAt: now}
+}
+
+// get returns a copy of the last good frame of the query with a warning notice about its age and the upstream error
+func (s *staleFrames) get(query models.Query, maxAge time.Duration, upstreamErr error, now time.Time) (*data.Frame, bool) {
+	key, ok := getStaleFrameKey(query)
+	if !ok {
+		return nil, false
+	}
+	s.mu.Lock()
+	stale, ok := s.frames[key]
+	s.mu.Unlock()
+	age := now.Sub(stale.createdAt)
+	if !ok || (maxAge > 0 && age > maxAge) {
+		return nil, false
+	}
+	frame := copyFrame(stale.frame)
+	frame.AppendNotices(data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("Showing the last successful response from %s ago as the upstream request failed. %s", age.Round(time.Second), upstreamErr.Error()),
+	})
+	return frame, true
+}
+
+// copyFrame returns a shallow copy of the frame with its own metadata, so that the metadata changes don't affect the stored frame
+func copyFrame(frame *data.Frame) *data.Frame {
+	copied := *frame
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		meta.Notices = append([]data.Notice{}, frame.Meta.Notices...)
+		if customMeta, ok := frame.Meta.Custom.(*CustomMeta); ok {
+			c := *customMeta
+			meta.Custom = &c
+		}
+		copied.Meta = &meta
+	}
+	return &copied
+}
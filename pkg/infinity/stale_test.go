@@ -0,0 +1,127 @@
+package infinity_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestServeStaleOnError(t *testing.T) {
+	var mu sync.Mutex
+	failingStatus := 0
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failingStatus != 0 {
+			w.WriteHeader(failingStatus)
+			return
+		}
+		_, _ = io.WriteString(w, `[{"id":1},{"id":2}]`)
+	})
+	queryJSON := []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/foo" }`, server.URL))
+	t.Run("should serve the last successful response with a warning when the upstream errors", func(t *testing.T) {
+		mu.Lock()
+		failingStatus = 0
+		mu.Unlock()
+		client := newTestClient(t, models.InfinitySettings{ServeStaleOnError: true})
+		res := queryData(context.Background(), backend.DataQuery{JSON: queryJSON}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Empty(t, res.Frames[0].Meta.Notices)
+		mu.Lock()
+		failingStatus = http.StatusInternalServerError
+		mu.Unlock()
+		res = queryData(context.Background(), backend.DataQuery{JSON: queryJSON}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, res.Frames[0].Meta.Notices[0].Severity)
+		assert.Contains(t, res.Frames[0].Meta.Notices[0].Text, "last successful response")
+	})
+	t.Run("should not serve the responses older than the max age", func(t *testing.T) {
+		mu.Lock()
+		failingStatus = 0
+		mu.Unlock()
+		client := newTestClient(t, models.InfinitySettings{ServeStaleOnError: true, StaleMaxAgeInSeconds: 1})
+		res := queryData(context.Background(), backend.DataQuery{JSON: queryJSON}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		mu.Lock()
+		failingStatus = http.StatusInternalServerError
+		mu.Unlock()
+		time.Sleep(1100 * time.Millisecond)
+		res = queryData(context.Background(), backend.DataQuery{JSON: queryJSON}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+	})
+	t.Run("should serve the last successful response when the upstream is unreachable", func(t *testing.T) {
+		unreachableServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `[{"id":1}]`)
+		}))
+		client := newTestClient(t, models.InfinitySettings{ServeStaleOnError: true})
+		unreachableQueryJSON := []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/foo" }`, unreachableServer.URL))
+		res := queryData(context.Background(), backend.DataQuery{JSON: unreachableQueryJSON}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		unreachableServer.Close()
+		res = queryData(context.Background(), backend.DataQuery{JSON: unreachableQueryJSON}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 1, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+	})
+	t.Run("should not serve the last successful response for the client errors", func(t *testing.T) {
+		mu.Lock()
+		failingStatus = 0
+		mu.Unlock()
+		client := newTestClient(t, models.InfinitySettings{ServeStaleOnError: true})
+		res := queryData(context.Background(), backend.DataQuery{JSON: queryJSON}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+			mu.Lock()
+			failingStatus = status
+			mu.Unlock()
+			res = queryData(context.Background(), backend.DataQuery{JSON: queryJSON}, *client, map[string]string{})
+			require.NotNil(t, res.Error)
+			assert.Equal(t, fmt.Sprintf("%d %s", status, http.StatusText(status)), res.Error.Error())
+		}
+	})
+	t.Run("should not serve the last successful response when the user identity is forwarded", func(t *testing.T) {
+		mu.Lock()
+		failingStatus = 0
+		mu.Unlock()
+		client := newTestClient(t, models.InfinitySettings{ServeStaleOnError: true, ForwardOauthIdentity: true})
+		res := queryData(context.Background(), backend.DataQuery{JSON: queryJSON}, *client, map[string]string{"Authorization": "Bearer user-1"})
+		require.Nil(t, res.Error)
+		mu.Lock()
+		failingStatus = http.StatusInternalServerError
+		mu.Unlock()
+		res = queryData(context.Background(), backend.DataQuery{JSON: queryJSON}, *client, map[string]string{"Authorization": "Bearer user-2"})
+		require.NotNil(t, res.Error)
+	})
+	t.Run("should not serve the last successful response of another time range for the time window queries", func(t *testing.T) {
+		mu.Lock()
+		failingStatus = 0
+		mu.Unlock()
+		client := newTestClient(t, models.InfinitySettings{ServeStaleOnError: true})
+		timeWindowQueryJSON := []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/foo", "pagination_mode": "time_window", "pagination_time_window_duration": "1d" }`, server.URL))
+		from := time.Unix(1700000000, 0)
+		res := queryData(context.Background(), backend.DataQuery{JSON: timeWindowQueryJSON, TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)}}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		mu.Lock()
+		failingStatus = http.StatusInternalServerError
+		mu.Unlock()
+		res = queryData(context.Background(), backend.DataQuery{JSON: timeWindowQueryJSON, TimeRange: backend.TimeRange{From: from.Add(time.Hour), To: from.Add(2 * time.Hour)}}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		res = queryData(context.Background(), backend.DataQuery{JSON: timeWindowQueryJSON, TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)}}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+		assert.Contains(t, res.Frames[0].Meta.Notices[0].Text, "last successful response")
+	})
+}
@@ -0,0 +1,69 @@
+package infinity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+/*
+ * getTimeWindows splits the time range of the query into consecutive windows of `pagination_time_window_duration`, oldest first.
+ * The last window ends at the end of the time range. For example, 30 days time range with 1d duration results 30 windows
+ *
+ * Error is returned when the time range requires more windows than the max pages of the query, instead of silently dropping the older windows.
+ * The max pages of the time windows defaults to and is limited by the pagination max pages of the datasource, same as the other pagination modes
+ */
+func getTimeWindows(query models.Query, maxPages int) ([]backend.TimeRange, error) {
+	duration, err := gtime.ParseDuration(query.PageTimeWindowDuration)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid pagination_time_window_duration %s", query.PageTimeWindowDuration)
+	}
+	from, to := query.TimeRange.From, query.TimeRange.To
+	if from.IsZero() || to.IsZero() || !from.Before(to) {
+		return nil, errors.New("invalid time range for time window pagination")
+	}
+	windows := []backend.TimeRange{}
+	for start := from; start.Before(to); start = start.Add(duration) {
+		if len(windows) >= maxPages {
+			return nil, fmt.Errorf("time range requires more than %d time windows of %s. increase the time window duration, the max pages of the query or the pagination max pages of the datasource", maxPages, query.PageTimeWindowDuration)
+		}
+		end := start.Add(duration)
+		if end.After(to) {
+			end = to
+		}
+		windows = append(windows, backend.TimeRange{From: start, To: end})
+	}
+	return windows, nil
+}
+
+// formatTimeWindowValue returns the start or end of the time window in the format of the query
+func formatTimeWindowValue(t time.Time, format models.TimeWindowFormat) string {
+	switch format {
+	case models.TimeWindowFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case models.TimeWindowFormatUnixMs:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.UTC().Format(time.RFC3339)
+	}
+}
+
+// withoutEmptyFrames removes the frames of the windows without data as they can't be merged with the frames having fields
+func withoutEmptyFrames(frames []*data.Frame) []*data.Frame {
+	out := []*data.Frame{}
+	for _, frame := range frames {
+		if frame != nil && frame.Rows() > 0 {
+			out = append(out, frame)
+		}
+	}
+	if len(out) == 0 && len(frames) > 0 {
+		return frames[:1]
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package infinity_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestTimeWindowPagination(t *testing.T) {
+	var mu sync.Mutex
+	windows := [][]string{}
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		from, to := r.URL.Query().Get("start"), r.URL.Query().Get("end")
+		if r.URL.Path == "/headers" {
+			from, to = r.Header.Get("X-From"), r.Header.Get("X-To")
+		}
+		mu.Lock()
+		windows = append(windows, []string{from, to})
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		start, _ := strconv.ParseInt(from, 10, 64)
+		if start == 1700086400 {
+			// no data in the second window
+			_, _ = io.WriteString(w, `[]`)
+			return
+		}
+		_, _ = io.WriteString(w, fmt.Sprintf(`[{ "start": "%s", "end": "%s" }]`, from, to))
+	})
+	client := newTestClient(t, models.InfinitySettings{})
+	from := time.Unix(1700000000, 0)
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		windows = [][]string{}
+	}
+	t.Run("should split the time range into windows and merge the frames in time order", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON:      []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/items", "pagination_mode": "time_window", "pagination_time_window_duration": "1d", "pagination_time_window_format": "unix", "pagination_param_start_field_name": "start", "pagination_param_end_field_name": "end", "pagination_parallelism": 3 }`, server.URL)),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(60 * time.Hour)},
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		field, _ := res.Frames[0].FieldByName("start")
+		require.NotNil(t, field)
+		first, _ := field.ConcreteAt(0)
+		last, _ := field.ConcreteAt(1)
+		require.Equal(t, "1700000000", first)
+		require.Equal(t, "1700172800", last)
+		field, _ = res.Frames[0].FieldByName("end")
+		end, _ := field.ConcreteAt(1)
+		require.Equal(t, "1700216000", end)
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 3, len(windows))
+	})
+	t.Run("should set the window in the headers with rfc3339 format", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON:      []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/headers", "pagination_mode": "time_window", "pagination_time_window_duration": "12h", "pagination_param_start_field_name": "X-From", "pagination_param_start_field_type": "header", "pagination_param_end_field_name": "X-To", "pagination_param_end_field_type": "header" }`, server.URL)),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(24 * time.Hour)},
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, [][]string{{"2023-11-14T22:13:20Z", "2023-11-15T10:13:20Z"}, {"2023-11-15T10:13:20Z", "2023-11-15T22:13:20Z"}}, windows)
+	})
+	t.Run("should limit the time windows by the pagination max pages of the datasource", func(t *testing.T) {
+		reset()
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON:      []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/items", "pagination_mode": "time_window", "pagination_time_window_duration": "1h", "pagination_max_pages": 1000 }`, server.URL)),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(30 * 24 * time.Hour)},
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "time range requires more than 5 time windows of 1h")
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 0, len(windows))
+	})
+	t.Run("should default the time windows to the pagination max pages of the datasource", func(t *testing.T) {
+		reset()
+		client := newTestClient(t, models.InfinitySettings{PaginationMaxPages: 30})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON:      []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/items", "pagination_mode": "time_window", "pagination_time_window_duration": "1d", "pagination_param_start_field_name": "start" }`, server.URL)),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(30 * 24 * time.Hour)},
+		}, *client, map[string]string{})
+		require.Nil(t, res.Error)
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, 30, len(windows))
+	})
+	t.Run("should fail when the time range requires more windows than the max pages", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{PaginationMaxPages: 30})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON:      []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/items", "pagination_mode": "time_window", "pagination_time_window_duration": "1d", "pagination_max_pages": 10 }`, server.URL)),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(30 * 24 * time.Hour)},
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "time range requires more than 10 time windows of 1d")
+	})
+	t.Run("should fail when the time range requires more than 1000 windows", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{PaginationMaxPages: 1000})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON:      []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/items", "pagination_mode": "time_window", "pagination_time_window_duration": "1h", "pagination_max_pages": 5000 }`, server.URL)),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(60 * 24 * time.Hour)},
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "time range requires more than 1000 time windows of 1h")
+	})
+	t.Run("should validate the window duration", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON:      []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "parser": "backend", "url": "%s/items", "pagination_mode": "time_window", "pagination_time_window_duration": "foo" }`, server.URL)),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+		}, *client, map[string]string{})
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "invalid pagination_time_window_duration foo")
+	})
+}
@@ -0,0 +1,109 @@
+package infinity
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// getTLSClientCertificate returns the client certificate from the base64 encoded PKCS#12 bundle when provided, otherwise from the PEM encoded certificate and key
+func getTLSClientCertificate(settings models.InfinitySettings) (tls.Certificate, error) {
+	if settings.TLSClientPKCS12 != "" {
+		return decodePKCS12(settings.TLSClientPKCS12, settings.TLSClientPKCS12Password)
+	}
+	if settings.TLSClientCert == "" || settings.TLSClientKey == "" {
+		return tls.Certificate{}, errors.New("invalid Client cert or key")
+	}
+	return tls.X509KeyPair([]byte(settings.TLSClientCert), []byte(settings.TLSClientKey))
+}
+
+// decodePKCS12 decodes the base64 encoded PKCS#12 bundle into the client certificate along with its chain of CA certificates
+func decodePKCS12(bundle string, password string) (tls.Certificate, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(bundle), ""))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid PKCS#12 client certificate. bundle must be base64 encoded. %w", err)
+	}
+	key, leaf, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("invalid PKCS#12 client certificate. %w", err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{leaf.Raw}, PrivateKey: key, Leaf: leaf}
+	for _, caCert := range caCerts {
+		cert.Certificate = append(cert.Certificate, caCert.Raw)
+	}
+	return cert, nil
+}
+
+// verifyPinnedPublicKeys verifies that one of the certificates presented by the pinned hosts has one of the pinned public keys (SPKI sha256 hash).
+// Connections to the other hosts such as the oauth token endpoints are not affected by the pins
+func verifyPinnedPublicKeys(pinnedHosts []string, pinnedPublicKeys [][]byte) func(cs tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if !slices.Contains(pinnedHosts, strings.ToLower(cs.ServerName)) {
+			return nil
+		}
+		for _, cert := range cs.PeerCertificates {
+			hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			for _, pinnedPublicKey := range pinnedPublicKeys {
+				if bytes.Equal(hash[:], pinnedPublicKey) {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("tls public key pinning failed for %s. none of the server certificates match the pinned public keys", cs.ServerName)
+	}
+}
+
+// TLSCertificateInfo is the summary of a certificate configured in the settings
+type TLSCertificateInfo struct {
+	Name     string
+	Subject  string
+	NotAfter time.Time
+}
+
+// GetTLSCertificatesInfo returns the subject and expiry of the client and CA certificates configured in the settings
+func GetTLSCertificatesInfo(settings models.InfinitySettings) ([]TLSCertificateInfo, error) {
+	out := []TLSCertificateInfo{}
+	if settings.TLSClientAuth {
+		cert, err := getTLSClientCertificate(settings)
+		if err != nil {
+			return out, err
+		}
+		if len(cert.Certificate) > 0 {
+			leaf, err := x509.ParseCertificate(cert.Certificate[0])
+			if err != nil {
+				return out, fmt.Errorf("invalid tls client certificate. %w", err)
+			}
+			out = append(out, TLSCertificateInfo{Name: "client certificate", Subject: leaf.Subject.String(), NotAfter: leaf.NotAfter})
+		}
+	}
+	if settings.TLSAuthWithCACert && settings.TLSCACert != "" {
+		rest := []byte(settings.TLSCACert)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return out, fmt.Errorf("invalid tls CA certificate. %w", err)
+			}
+			out = append(out, TLSCertificateInfo{Name: "CA certificate", Subject: cert.Subject.String(), NotAfter: cert.NotAfter})
+		}
+	}
+	return out, nil
+}
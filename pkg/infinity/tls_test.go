@@ -0,0 +1,169 @@
+package infinity_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+// mockPKCS12Bundle is a base64 encoded PKCS#12 bundle with a self-signed certificate for CN=infinity-test protected with the password "secret"
+const mockPKCS12Bundle = `MIIDigIBAzCCA1AGCSqGSIb3DQEHAaCCA0EEggM9MIIDOTCCAi8GCSqGSIb3DQEHBqCCAiAwggIcAgEAMIICFQYJKoZIhvcNAQcBMBwGCiqGSIb3DQEMAQYwDgQIlKe6HYL64+UCAggAgIIB6OtnnQtGlyinzQFtY/7Yi3bmrl3OUbRQnF5MbkuuXvdENtT40Im+lfvtVcHjg6XvsGMx9AczuqyY3BEtRgbvLpBtsljk6fGWwTlvP+YM7b4jJt+Fk0E7PJ3NK10D4IS+Pqie/pRN7DS5lPLKe1CJdbakMjWiE5xfLI9gtx7cppugA/jcAWUripCc+qmks+JbQu0Z6eoeyR8LH+NyD5ATRpuWVe6z+F7ZXHCVQvafDa/JvVUUOpwKsF1JbfLHqOIn/25zFP6j7Mw90eyVSxKlmlOoXs9pwxer4Zly+kpJfx6rGrmrDISu3q+DYWOBc79xJSMvqz4fNt2z6EvWSt0L9RPh3jd829T0CjyzF2gZp5uY1YUj3/kWESN7vOOvgbkJft1B/zRx1REFeb2gIBV75cthQ9s7RbtT9TbAG/rGwL2wvovuIeK0kmzig9aGPNBqxmAO0+U4dn9D95zhcbZ/Qy5UdSwVDYFSZqpLEGwckf9YqiXd4Eh6ZNBH67gXHT14FbUSjq8fL2LbFZFVLVShXRq7A5FdcDv6Pmx37zN/4/E7u6zXKaWCHUpKeyLW9pHtGvp/Mit6keXaBV47Pzwc0qE/X4ntmt3HliFlQLuqAIaqlEQf3BiHkkU7T14JG9D3UIU56tA2HS61MIIBAgYJKoZIhvcNAQcBoIH0BIHxMIHuMIHrBgsqhkiG9w0BDAoBAqCBtDCBsTAcBgoqhkiG9w0BDAEDMA4ECCH+92wD5Ze5AgIIAASBkDi7jGM7iwVcLVHKWmnyhJ/1JW3lETIGklm4nbGYSth8u63ypAbHyCaC7vp0KjCRolOpeChukIttX71e2Nml8JiGErVGk5+XX7vLiU3CB7ea5D+TZrg/wMz9COYIprx7vThzZXZDJwDb5gRi1OmEqwBt38qPBHrTmxNNs1YR2QNSD5G+UF4b1w/6wcjbNV97HzElMCMGCSqGSIb3DQEJFTEWBBQceXraxkhNVEh/XcK7/DLcY6kIETAxMCEwCQYFKw4DAhoFAAQUi+/yv04/zFV7zlrX4Y2FG8P89EEECJ7475bQrSHYAgIIAA==`
+
+func TestGetTLSConfigFromSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings models.InfinitySettings
+		wantErr  string
+		check    func(t *testing.T, tlsConfig *tls.Config)
+	}{
+		{
+			name:     "should load the client certificate from PKCS#12 bundle",
+			settings: models.InfinitySettings{TLSClientAuth: true, TLSClientPKCS12: mockPKCS12Bundle, TLSClientPKCS12Password: "secret"},
+			check: func(t *testing.T, tlsConfig *tls.Config) {
+				require.Len(t, tlsConfig.Certificates, 1)
+				assert.NotNil(t, tlsConfig.Certificates[0].PrivateKey)
+			},
+		},
+		{
+			name:     "should error with invalid PKCS#12 password",
+			settings: models.InfinitySettings{TLSClientAuth: true, TLSClientPKCS12: mockPKCS12Bundle, TLSClientPKCS12Password: "foo"},
+			wantErr:  "invalid PKCS#12 client certificate. pkcs12: decryption password incorrect",
+		},
+		{
+			name:     "should error with invalid PKCS#12 encoding",
+			settings: models.InfinitySettings{TLSClientAuth: true, TLSClientPKCS12: "%%%"},
+			wantErr:  "invalid PKCS#12 client certificate. bundle must be base64 encoded. illegal base64 data at input byte 0",
+		},
+		{
+			name:     "should apply min version and cipher suites",
+			settings: models.InfinitySettings{TLSMinVersion: "1.2", TLSCipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}},
+			check: func(t *testing.T, tlsConfig *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+				assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, tlsConfig.CipherSuites)
+				assert.Nil(t, tlsConfig.VerifyConnection)
+			},
+		},
+		{
+			name:     "should error with insecure cipher suites",
+			settings: models.InfinitySettings{TLSCipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			wantErr:  "invalid tls cipher suite TLS_RSA_WITH_RC4_128_SHA",
+		},
+		{
+			name:     "should verify the pinned public keys",
+			settings: models.InfinitySettings{TLSPinnedPublicKeys: []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}, TLSPinnedHosts: []string{"Foo.com"}},
+			check: func(t *testing.T, tlsConfig *tls.Config) {
+				require.NotNil(t, tlsConfig.VerifyConnection)
+				assert.NotNil(t, tlsConfig.VerifyConnection(tls.ConnectionState{ServerName: "foo.com"}))
+				assert.Nil(t, tlsConfig.VerifyConnection(tls.ConnectionState{ServerName: "login.microsoftonline.com"}))
+			},
+		},
+		{
+			name:     "should error when the pinned public keys have no hosts",
+			settings: models.InfinitySettings{TLSPinnedPublicKeys: []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}},
+			wantErr:  "tls pinned public keys require the hosts they apply to",
+		},
+		{
+			name:     "should error when the pinned host is an ip address",
+			settings: models.InfinitySettings{TLSPinnedPublicKeys: []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}, TLSPinnedHosts: []string{"127.0.0.1"}},
+			wantErr:  "invalid tls pinned host 127.0.0.1. pinned hosts must be host names without scheme or port",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := infinity.GetTLSConfigFromSettings(tt.settings)
+			if tt.wantErr != "" {
+				require.NotNil(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.Nil(t, err)
+			tt.check(t, tlsConfig)
+		})
+	}
+}
+
+func TestGetTLSCertificatesInfo(t *testing.T) {
+	certs, err := infinity.GetTLSCertificatesInfo(models.InfinitySettings{TLSClientAuth: true, TLSClientPKCS12: mockPKCS12Bundle, TLSClientPKCS12Password: "secret"})
+	require.Nil(t, err)
+	require.Len(t, certs, 1)
+	assert.Equal(t, "client certificate", certs[0].Name)
+	assert.Equal(t, "CN=infinity-test", certs[0].Subject)
+	assert.Equal(t, 2126, certs[0].NotAfter.Year())
+}
+
+func TestTLSPinnedPublicKeys(t *testing.T) {
+	server := newTestTLSServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{ "message" : "OK" }`)
+	})
+	serverURL, err := url.Parse(server.URL)
+	require.Nil(t, err)
+	publicKeyHash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	for _, tt := range []struct {
+		name    string
+		pin     string
+		host    string
+		wantErr bool
+	}{
+		{name: "should allow the pinned host with matching key", pin: "sha256/" + base64.StdEncoding.EncodeToString(publicKeyHash[:]), host: "example.com"},
+		{name: "should reject the pinned host with different key", pin: "sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", host: "example.com", wantErr: true},
+		{name: "should not apply the pins to the other hosts", pin: "sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", host: "token.example.com"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, models.InfinitySettings{
+				AuthenticationMethod: models.AuthenticationMethodNone,
+				InsecureSkipVerify:   true,
+				TLSPinnedPublicKeys:  []string{tt.pin},
+				TLSPinnedHosts:       []string{"example.com"},
+				DNSOverrides: []models.DNSOverride{
+					{Host: "example.com", IP: serverURL.Hostname()},
+					{Host: "token.example.com", IP: serverURL.Hostname()},
+				},
+			})
+			res := queryData(context.Background(), backend.DataQuery{
+				JSON: []byte(fmt.Sprintf(`{ "type": "json", "url": "https://%s:%s", "source": "url" }`, tt.host, serverURL.Port())),
+			}, *client, map[string]string{})
+			require.NotNil(t, res)
+			if tt.wantErr {
+				require.NotNil(t, res.Error)
+				assert.Contains(t, res.Error.Error(), "tls public key pinning failed for example.com")
+				return
+			}
+			require.Nil(t, res.Error)
+			metaData := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+			require.Equal(t, map[string]any{"message": "OK"}, metaData.Data)
+		})
+	}
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{ "message" : "OK" }`)
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+	client := newTestClient(t, models.InfinitySettings{
+		URL:                  server.URL,
+		AuthenticationMethod: models.AuthenticationMethodNone,
+		InsecureSkipVerify:   true,
+		TLSMinVersion:        "1.3",
+	})
+	res := queryData(context.Background(), backend.DataQuery{
+		JSON: []byte(fmt.Sprintf(`{ "type": "json", "url": "%s", "source": "url" }`, server.URL)),
+	}, *client, map[string]string{})
+	require.NotNil(t, res)
+	require.NotNil(t, res.Error)
+	assert.Contains(t, res.Error.Error(), "protocol version")
+}
@@ -0,0 +1,67 @@
+package infinity
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+)
+
+// getTransport returns the tuned transport of the datasource instance. The transport is shared by all the requests and auth wrappers of the client
+func getTransport(settings models.InfinitySettings) (*http.Transport, error) {
+	tlsConfig, err := GetTLSConfigFromSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	dialContext, err := getDialContext(settings)
+	if err != nil {
+		return nil, err
+	}
+	transportSettings := settings.TransportSettings
+	transport := &http.Transport{
+		TLSClientConfig:       tlsConfig,
+		DialContext:           dialContext,
+		MaxIdleConns:          withDefault(transportSettings.MaxIdleConns, defaultMaxIdleConns),
+		MaxIdleConnsPerHost:   withDefault(transportSettings.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost),
+		IdleConnTimeout:       withDefaultDuration(transportSettings.IdleConnTimeoutInSeconds, defaultIdleConnTimeout),
+		TLSHandshakeTimeout:   withDefaultDuration(transportSettings.TLSHandshakeTimeoutInSeconds, defaultTLSHandshakeTimeout),
+		DisableKeepAlives:     transportSettings.DisableKeepAlives,
+		ForceAttemptHTTP2:     transportSettings.EnableHTTP2,
+		ExpectContinueTimeout: time.Second,
+	}
+	if err := applyProxy(transport, settings); err != nil {
+		return nil, err
+	}
+	return transport, nil
+}
+
+func getDialer(settings models.InfinitySettings) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   withDefaultDuration(settings.TransportSettings.DialTimeoutInSeconds, defaultDialTimeout),
+		KeepAlive: withDefaultDuration(settings.TransportSettings.KeepAliveInSeconds, defaultKeepAlive),
+	}
+}
+
+func withDefault(value int, defaultValue int) int {
+	if value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func withDefaultDuration(seconds int, defaultValue time.Duration) time.Duration {
+	if seconds <= 0 {
+		return defaultValue
+	}
+	return time.Duration(seconds) * time.Second
+}
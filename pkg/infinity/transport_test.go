@@ -0,0 +1,55 @@
+package infinity_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestSharedTransport(t *testing.T) {
+	var mu sync.Mutex
+	connStates := map[http.ConnState]int{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"message":"OK"}`)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		connStates[state]++
+	}
+	server.StartTLS()
+	defer server.Close()
+	getConnStates := func(state http.ConnState) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return connStates[state]
+	}
+	client := newTestClient(t, models.InfinitySettings{
+		AuthenticationMethod: models.AuthenticationMethodAWS,
+		AWSAccessKey:         "access-key",
+		AWSSecretKey:         "secret-key",
+		AllowedHosts:         []string{server.URL},
+		InsecureSkipVerify:   true,
+		TransportSettings:    models.TransportSettings{MaxIdleConnsPerHost: 2, IdleConnTimeoutInSeconds: 60, EnableHTTP2: true},
+	})
+	for i := 0; i < 3; i++ {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "url": "%s/foo" }`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+	}
+	require.Equal(t, 1, getConnStates(http.StateNew))
+	client.Dispose()
+	require.Eventually(t, func() bool { return getConnStates(http.StateClosed) == 1 }, time.Second, 10*time.Millisecond)
+}
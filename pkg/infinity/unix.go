@@ -0,0 +1,108 @@
+package infinity
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+/*
+ * Unix domain socket urls are in the format unix://<socket path>:<request path>, for example
+ *
+ *		unix:///var/run/docker.sock:/containers/json?all=true
+ *
+ * The requests are sent as http requests to the host `<hex encoded socket path>.unix` so that each socket gets
+ * its own connection pool in the transport. The dialer connects to the socket path encoded in the host name only when
+ * the same socket path is marked in the request context after the allowed hosts check. So the redirects and the other
+ * requests can't reach the unix sockets by using the `.unix` host names.
+ */
+const (
+	unixSocketURLPrefix  = "unix://"
+	unixSocketHostSuffix = ".unix"
+)
+
+// splitUnixSocketURL returns the socket path and the request path (including the query string) of the unix socket url
+func splitUnixSocketURL(u string) (socketPath string, requestPath string, ok bool) {
+	if !strings.HasPrefix(u, unixSocketURLPrefix) {
+		return "", "", false
+	}
+	socketPath, requestPath, _ = strings.Cut(strings.TrimPrefix(u, unixSocketURLPrefix), ":")
+	if !strings.HasPrefix(requestPath, "/") {
+		requestPath = "/" + requestPath
+	}
+	return socketPath, requestPath, socketPath != ""
+}
+
+// getUnixSocketRequestURL returns the http url used for the requests to the unix socket url
+func getUnixSocketRequestURL(u string) (string, error) {
+	socketPath, requestPath, ok := splitUnixSocketURL(u)
+	if !ok {
+		return u, errors.New("invalid unix socket url")
+	}
+	return "http://" + hex.EncodeToString([]byte(socketPath)) + unixSocketHostSuffix + requestPath, nil
+}
+
+// getUnixSocketPath returns the socket path encoded in the host name of the request
+func getUnixSocketPath(host string) (string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if !strings.HasSuffix(host, unixSocketHostSuffix) {
+		return "", false
+	}
+	socketPath, err := hex.DecodeString(strings.TrimSuffix(host, unixSocketHostSuffix))
+	if err != nil || len(socketPath) == 0 {
+		return "", false
+	}
+	return string(socketPath), true
+}
+
+// getUnixSocketURL returns the unix socket url of the given url. Both the unix socket urls and the unix socket request urls are supported
+func getUnixSocketURL(u string) (string, bool) {
+	if _, _, ok := splitUnixSocketURL(u); ok {
+		return u, true
+	}
+	parsedURL, err := url.Parse(u)
+	if err != nil {
+		return "", false
+	}
+	socketPath, ok := getUnixSocketPath(parsedURL.Host)
+	if !ok {
+		return "", false
+	}
+	return unixSocketURLPrefix + socketPath + ":" + parsedURL.RequestURI(), true
+}
+
+// canAllowUnixSocketURL returns true when the socket path is explicitly allowed. Allowed hosts can optionally restrict the request path, for example unix:///var/run/docker.sock:/containers
+func canAllowUnixSocketURL(u string, allowedHosts []string) bool {
+	socketPath, requestPath, _ := splitUnixSocketURL(u)
+	for _, host := range allowedHosts {
+		allowedSocketPath, allowedPath, ok := splitUnixSocketURL(host)
+		if ok && allowedSocketPath == socketPath && strings.HasPrefix(requestPath, allowedPath) {
+			return true
+		}
+	}
+	return false
+}
+
+type unixSocketContextKey struct{}
+
+// withAllowedUnixSocket marks the socket path as allowed for the requests of the context. Must be used only after the allowed hosts check
+func withAllowedUnixSocket(ctx context.Context, socketPath string) context.Context {
+	return context.WithValue(ctx, unixSocketContextKey{}, socketPath)
+}
+
+// isAllowedUnixSocket returns true when the socket path is marked as allowed in the context
+func isAllowedUnixSocket(ctx context.Context, socketPath string) bool {
+	allowedSocketPath, ok := ctx.Value(unixSocketContextKey{}).(string)
+	return ok && allowedSocketPath == socketPath
+}
+
+func isUnixSocketRequest(req *http.Request) bool {
+	_, ok := getUnixSocketPath(req.URL.Host)
+	return ok
+}
@@ -0,0 +1,98 @@
+package infinity_test
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestUnixSocket(t *testing.T) {
+	socketDir, err := os.MkdirTemp("", "infinity")
+	require.Nil(t, err)
+	defer os.RemoveAll(socketDir)
+	socketPath := filepath.Join(socketDir, "api.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.Nil(t, err)
+	var socketRequests atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		socketRequests.Add(1)
+		if r.URL.Path != "/containers/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, fmt.Sprintf(`[{"id":"c%s"}]`, r.URL.Query().Get("page")))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+	t.Run("should query the unix socket with backend parser and pagination", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{
+			URL:          "unix://" + socketPath + ":",
+			AllowedHosts: []string{"unix://" + socketPath + ":/containers"},
+		})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(`{
+				"type": "json",
+				"source": "url",
+				"parser": "backend",
+				"url":  "/containers/json",
+				"pagination_mode": "page",
+				"pagination_max_pages": 2
+			}`),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		require.Equal(t, 2, res.Frames[0].Rows())
+	})
+	t.Run("should not query the unix socket which is not allowed", func(t *testing.T) {
+		client := newTestClient(t, models.InfinitySettings{})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "url": "unix://%s:/containers/json" }`, socketPath)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.NotNil(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "requested URL is not allowed")
+	})
+	t.Run("should not follow the redirects to the unix socket", func(t *testing.T) {
+		var otherRequests atomic.Int32
+		otherServer := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			otherRequests.Add(1)
+			_, _ = io.WriteString(w, `{"message":"OK"}`)
+		})
+		redirectServer := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/unix":
+				http.Redirect(w, r, "http://"+hex.EncodeToString([]byte(socketPath))+".unix/containers/json", http.StatusFound)
+			default:
+				http.Redirect(w, r, otherServer.URL, http.StatusFound)
+			}
+		})
+		client := newTestClient(t, models.InfinitySettings{
+			AllowedHosts: []string{redirectServer.URL, "unix://" + socketPath + ":/containers"},
+		})
+		socketRequestsBefore := socketRequests.Load()
+		for _, path := range []string{"/unix", "/other"} {
+			res := queryData(context.Background(), backend.DataQuery{
+				JSON: []byte(fmt.Sprintf(`{ "type": "json", "source": "url", "url": "%s%s" }`, redirectServer.URL, path)),
+			}, *client, map[string]string{})
+			require.NotNil(t, res)
+			require.NotNil(t, res.Error)
+			assert.Contains(t, res.Error.Error(), fmt.Sprintf("error getting response from %s%s", redirectServer.URL, path))
+		}
+		assert.Equal(t, socketRequestsBefore, socketRequests.Load())
+		assert.Equal(t, int32(0), otherRequests.Load())
+	})
+}
@@ -0,0 +1,99 @@
+package infinity
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/mercury"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/zcap"
+)
+
+// ApplyZCapInvocation signs the requests natively with zCap capability invocations when the zcap mode is native
+func ApplyZCapInvocation(ctx context.Context, httpClient *http.Client, settings models.InfinitySettings) (*http.Client, error) {
+	_, span := tracing.DefaultTracer().Start(ctx, "ApplyZCapInvocation")
+	defer span.End()
+	if settings.AuthenticationMethod != models.AuthenticationMethodZCAP || settings.ZCapSettings.Mode != models.ZCapModeNative {
+		return httpClient, nil
+	}
+	key, err := zcap.ParseKey(settings.ZCapSettings.PrivateKey, settings.ZCapSettings.KeyID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return &http.Client{
+		Transport: &zcap.Transport{
+			Invoker: &zcap.Invoker{
+				Key:              key,
+				Capability:       settings.ZCapSettings.Capability,
+				InvocationTarget: settings.ZCapSettings.InvocationTarget,
+				Action:           settings.ZCapSettings.Action,
+			},
+			Base: httpClient.Transport,
+		},
+		Timeout: httpClient.Timeout,
+	}, nil
+}
+
+func isNativeZCap(settings models.InfinitySettings) bool {
+	return settings.AuthenticationMethod == models.AuthenticationMethodZCAP && settings.ZCapSettings.Mode == models.ZCapModeNative
+}
+
+// reqWithMercury performs the zCap-authorized request using the Mercury Client Adapter with the url, method, headers and body of the query
+func (client *Client) reqWithMercury(ctx context.Context, url string, req *http.Request, query models.Query) (obj any, statusCode int, duration time.Duration, err error) {
+	_, span := tracing.DefaultTracer().Start(ctx, "client.reqWithMercury")
+	defer span.End()
+	body, err := getRequestBody(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, http.StatusInternalServerError, 0, fmt.Errorf("error reading request body. %w", err)
+	}
+	backend.Logger.Debug("yesoreyeram-infinity-datasource plugin is now requesting URL using mercury client", "url", req.URL.String())
+	startTime := time.Now()
+	res, err := client.mercuryRequest(ctx, mercury.OperationRequest, req.URL.String(), mercury.RequestOptions{
+		Method:  req.Method,
+		Headers: req.Header,
+		Body:    body,
+	})
+	duration = time.Since(startTime)
+	if err != nil {
+		span.RecordError(err)
+		backend.Logger.Error("error getting response from mercury client", "url", url, "method", req.Method, "error", err.Error())
+		return nil, http.StatusBadGateway, duration, fmt.Errorf("error getting response from url %s using mercury client. %w", url, err)
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		return nil, res.StatusCode, duration, fmt.Errorf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+	out, err := parseResponseBody(res.Data, query, res.Headers, url)
+	return out, res.StatusCode, duration, err
+}
+
+// mercuryRequest executes the operation using the persistent mercury client adapter pool when enabled, or a new adapter process otherwise
+func (client *Client) mercuryRequest(ctx context.Context, operation string, target string, options mercury.RequestOptions) (*mercury.Response, error) {
+	if client.MercuryPool != nil {
+		return client.MercuryPool.Request(ctx, operation, target, options)
+	}
+	return mercury.Request(ctx, getMercuryConfig(client.Settings), operation, target, options)
+}
+
+// getMercuryConfig returns the mercury client adapter process configuration from the zcap settings
+func getMercuryConfig(settings models.InfinitySettings) mercury.Config {
+	return mercury.Config{
+		Path:    settings.ZCapSettings.AdapterPath,
+		Args:    settings.ZCapSettings.AdapterArgs,
+		Timeout: time.Duration(settings.ZCapSettings.AdapterTimeoutInSeconds) * time.Second,
+	}
+}
+
+// getMercuryPool returns the pool of persistent mercury client adapter processes from the zcap settings
+func getMercuryPool(settings models.InfinitySettings) *mercury.Pool {
+	return mercury.NewPool(mercury.PoolConfig{
+		Config:         getMercuryConfig(settings),
+		Size:           settings.ZCapSettings.AdapterPoolSize,
+		MaxConcurrency: settings.ZCapSettings.AdapterMaxConcurrency,
+	})
+}
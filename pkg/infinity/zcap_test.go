@@ -0,0 +1,146 @@
+package infinity_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/zcap"
+)
+
+func TestZCapNativeInvocation(t *testing.T) {
+	t.Run("should sign the requests with zcap invocation", func(t *testing.T) {
+		privateKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			matches := regexp.MustCompile(`^Signature keyId="([^"]+)",headers="([^"]+)",signature="([^"]+)",created="(\d+)",expires="(\d+)"$`).FindStringSubmatch(r.Header.Get("Authorization"))
+			if len(matches) != 6 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "did:key:z6Mk#z6Mk", matches[1])
+			assert.Equal(t, fmt.Sprintf(`zcap id="%s",action="read"`, zcap.RootCapabilityID("http://"+r.Host+"/edvs/z123")), r.Header.Get("Capability-Invocation"))
+			created, _ := strconv.ParseInt(matches[4], 10, 64)
+			expires, _ := strconv.ParseInt(matches[5], 10, 64)
+			signature, _ := base64.StdEncoding.DecodeString(matches[3])
+			if !ed25519.Verify(privateKey.Public().(ed25519.PublicKey), []byte(zcap.GetSigningString(r, strings.Split(matches[2], " "), matches[1], created, expires)), signature) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"foo":"bar"}`)
+		})
+		client := newTestClient(t, models.InfinitySettings{
+			URL:                  server.URL,
+			AuthenticationMethod: models.AuthenticationMethodZCAP,
+			ZCapSettings: models.ZCapSettings{
+				Mode:       models.ZCapModeNative,
+				KeyID:      "did:key:z6Mk#z6Mk",
+				PrivateKey: base64.StdEncoding.EncodeToString(privateKey.Seed()),
+			},
+		})
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(fmt.Sprintf(`{
+				"type": "json",
+				"source": "url",
+				"url":  "%s/edvs/z123"
+			}`, server.URL)),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		metaData := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+		require.NotNil(t, metaData)
+		require.Equal(t, http.StatusOK, metaData.ResponseCodeFromServer)
+		require.Equal(t, map[string]any(map[string]any{"foo": "bar"}), metaData.Data)
+	})
+}
+
+func TestZCapMercuryAdapter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake mercury client adapter script requires a posix shell")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+[ "$1" = "--json" ] || { echo "unexpected args $*" >&2; exit 2; }
+input=$(cat)
+case "$input" in
+  *missing*) echo '{"status":404,"data":"not found"}' ;;
+  *) printf '{"status":200,"headers":{"Content-Type":["application/json"]},"dataEncoding":"base64","data":"%s"}\n' "$(printf '%s' "$input" | base64 | tr -d '\n')" ;;
+esac
+`
+	adapterPath := filepath.Join(dir, "fake-mercury-client")
+	require.Nil(t, os.WriteFile(adapterPath, []byte(script), 0o755))
+	t.Setenv(models.EnvZCapAdapterAllowedPaths, dir)
+	client := newTestClient(t, models.InfinitySettings{
+		AuthenticationMethod: models.AuthenticationMethodZCAP,
+		ZCapSettings:         models.ZCapSettings{AdapterPath: adapterPath, AdapterArgs: []string{"--json"}},
+	})
+	t.Run("should use the query url, method and body", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(`{
+				"type": "json",
+				"source": "url",
+				"url":  "https://foo.com/api?a=b",
+				"url_options": { "method": "POST", "data": "{\"foo\":\"bar\"}" }
+			}`),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.Nil(t, res.Error)
+		metaData := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+		require.Equal(t, http.StatusOK, metaData.ResponseCodeFromServer)
+		require.Equal(t, map[string]any{
+			"operation": "request",
+			"target":    "https://foo.com/api?a=b",
+			"method":    "POST",
+			"headers": map[string]any{
+				"Accept":       []any{"application/json;q=0.9,text/plain"},
+				"Content-Type": []any{"text/plain"},
+			},
+			"body": `{"foo":"bar"}`,
+		}, metaData.Data)
+	})
+	t.Run("should report the upstream status", func(t *testing.T) {
+		res := queryData(context.Background(), backend.DataQuery{
+			JSON: []byte(`{ "type": "json", "source": "url", "url":  "https://foo.com/missing" }`),
+		}, *client, map[string]string{})
+		require.NotNil(t, res)
+		require.NotNil(t, res.Error)
+		metaData := res.Frames[0].Meta.Custom.(*infinity.CustomMeta)
+		require.Equal(t, http.StatusNotFound, metaData.ResponseCodeFromServer)
+		require.Equal(t, "404 Not Found", metaData.Error)
+	})
+	t.Run("should not execute the adapters outside of the allowed adapter paths", func(t *testing.T) {
+		t.Setenv(models.EnvZCapAdapterAllowedPaths, t.TempDir())
+		_, err := infinity.NewClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodZCAP,
+			ZCapSettings:         models.ZCapSettings{AdapterPath: adapterPath},
+		})
+		require.NotNil(t, err)
+		require.Equal(t, fmt.Sprintf("invalid zcap adapter. zcap adapter %s is not in the allowed adapter paths", adapterPath), err.Error())
+	})
+	t.Run("should not start the persistent adapter pool outside of the allowed adapter paths", func(t *testing.T) {
+		t.Setenv(models.EnvZCapAdapterAllowedPaths, "")
+		client, err := infinity.NewClient(context.Background(), models.InfinitySettings{
+			AuthenticationMethod: models.AuthenticationMethodZCAP,
+			ZCapSettings:         models.ZCapSettings{AdapterPath: adapterPath, AdapterArgs: []string{"--json"}, PersistentAdapter: true},
+		})
+		require.NotNil(t, err)
+		require.Nil(t, client)
+		require.Equal(t, "invalid zcap adapter. custom zcap adapter path and arguments are not allowed. configure zcap_adapter_allowed_paths in the plugin config", err.Error())
+	})
+}
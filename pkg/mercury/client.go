@@ -2,112 +2,215 @@ package mercury
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os/exec"
+	"strings"
 	"time"
 )
 
+const (
+	// OperationRequest makes a zCap-authorized HTTP request
+	OperationRequest = "request"
+	// OperationDownload uses zCaps to download and decrypt an EDV document
+	OperationDownload = "download"
+	// OperationQuery uses zCaps to find and decrypt the EDV documents matching the DocumentQuery
+	OperationQuery = "query"
+)
+
+const (
+	DefaultPath    = "mercury-client"
+	DefaultTimeout = 60 * time.Second
+)
+
+var DefaultArgs = []string{"--json"}
+
+// Config of the Mercury Client Adapter process
+type Config struct {
+	// Path of the adapter binary. Defaults to `mercury-client` from PATH
+	Path string
+	// Args passed to the adapter binary. Defaults to `--json`
+	Args []string
+	// Timeout of a single operation. Defaults to 60 seconds
+	Timeout time.Duration
+}
+
+// RequestOptions are the HTTP request details passed to the Mercury Client Adapter for the "request" operation
+// and the document filters for the "query" operation
+type RequestOptions struct {
+	Method  string
+	Headers http.Header
+	Body    []byte
+	Query   *DocumentQuery
+}
+
+// DocumentQuery filters the EDV documents of the "query" operation using the blinded indexes of the vault
+type DocumentQuery struct {
+	Index  string              `json:"index,omitempty"`
+	Equals []map[string]string `json:"equals,omitempty"`
+	Has    []string            `json:"has,omitempty"`
+	Limit  int                 `json:"limit,omitempty"`
+}
+
+// Document is a decrypted EDV document
+type Document struct {
+	ID       string          `json:"id"`
+	Sequence int64           `json:"sequence"`
+	Meta     json.RawMessage `json:"meta,omitempty"`
+	Content  json.RawMessage `json:"content,omitempty"`
+}
+
+// Response is the structured output of the Mercury Client Adapter
+type Response struct {
+	// StatusCode of the upstream response
+	StatusCode int
+	// Headers of the upstream response
+	Headers http.Header
+	// Content is the EDV document `content` (where applicable)
+	Content json.RawMessage
+	// Data includes HTTP Response or EDV stream data
+	Data []byte
+	// Documents are the decrypted EDV documents of the "download" and "query" operations
+	Documents []Document
+}
+
+// adapterRequest is the JSON document written to the stdin of the adapter
+type adapterRequest struct {
+	ID        string         `json:"id,omitempty"`
+	Operation string         `json:"operation"`
+	Target    string         `json:"target"`
+	Method    string         `json:"method,omitempty"`
+	Headers   http.Header    `json:"headers,omitempty"`
+	Body      string         `json:"body,omitempty"`
+	Query     *DocumentQuery `json:"query,omitempty"`
+}
+
+// adapterResponse is the JSON document written by the adapter to its stdout
+type adapterResponse struct {
+	ID           string          `json:"id,omitempty"`
+	Status       int             `json:"status"`
+	Headers      http.Header     `json:"headers,omitempty"`
+	Content      json.RawMessage `json:"content,omitempty"`
+	Data         string          `json:"data,omitempty"`
+	DataEncoding string          `json:"dataEncoding,omitempty"` // '' | 'base64'
+	Documents    []Document      `json:"documents,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
 /*
- * Execute an operation using the Mercury Client Adapter Node.js process.
+ * Execute an operation using the Mercury Client Adapter process.
  *
  * Usage:
- *		content, data, err := mercury.Request(operation, target)
+ *		res, err := mercury.Request(ctx, config, operation, target, options)
+ *
+ * The adapter is started with the configured path and arguments. The operation is written to its stdin as a JSON document
+ *		{ "operation": "request", "target": "https://...", "method": "GET", "headers": { "Accept": ["application/json"] }, "body": "" }
+ * and the adapter is expected to write a single JSON document to its stdout
+ *		{ "status": 200, "headers": { ... }, "content": { ... }, "data": "...", "dataEncoding": "base64", "documents": [], "error": "" }
  *
  * operation string:
- *		"request" makes a zCap-authorized HTTP request
+ *		"request" makes a zCap-authorized HTTP request with the method, headers and body from options
  *		"download" uses zCaps to download and decrypt an EDV document
+ *		"query" uses zCaps to find and decrypt the EDV documents matching the query from options
  * target string:
  *		URL of the HTTP API or EDV resource
- * content []byte:
- * 		Portion of sdtout buffer returned by Mercury Client Adapter.
- *		Includes log messages and EDV document `content` (where applicable)
- * data []byte:
- *		Portion of stdout buffer returned by Mercury Client Adapter.
- *		Includes HTTP Response or EDV stream data
- * err error:
- *		Exit code from os/exec operation
- *		Note: Mercury Client Node.js error information is output to the console
+ *
+ * The process is killed when the context is cancelled or the configured timeout is reached.
+ * Anything written by the adapter to stderr is included in the returned error.
  */
-func Request(operation string, target string) ([]byte, []byte, error) {
-	// Declare variables
-	var qcontent []byte
-	var qdata []byte
-
-	// Collect results from StdOut
-	type output struct {
-		result []byte
-		stderr []byte
-		err    error
+func Request(ctx context.Context, config Config, operation string, target string, options RequestOptions) (*Response, error) {
+	input, err := json.Marshal(newAdapterRequest("", operation, target, options))
+	if err != nil {
+		return nil, fmt.Errorf("error encoding mercury client request. %w", err)
 	}
-	ch := make(chan output)
-
-	// Run Mercury Client Adapter Node.js process
-	go func() {
-		if operation == "download" {
-			// Requires version 3.0.0 of the client adapter
-			operation = "get -d"
-		}
-		//absexe, _ := filepath.Abs("C:/Program Files/nodejs/mercury-client") fork/exec /usr/share/grafana/C:\Program Files\nodejs\mercury-client: no such file or directory
-		//cmd.Dir = "C:/Program Files/nodejs/mercury-client" error getting data frame. chdir C:\Program Files\nodejs: no such file or directory
-
-		cmd := exec.Command("mercury-client", operation, target)
-
-		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-		err := cmd.Run()
-		ch <- output{result: stdout.Bytes(), stderr: stderr.Bytes(), err: err}
-	}()
-
-	// Handle Timeouts and Errors
-	select {
-	case <-time.After(60 * time.Second):
-		fmt.Println("Command timed out")
-	case x := <-ch:
-		if x.err != nil {
-			// Catch errors from node.js
-			fmt.Println("Error executing command:", string(x.stderr))
-			return nil, nil, x.err
-		}
-
-		var qindex int
-		var offset int
-		switch operation {
-		case "download":
-			// Find the start of the Stream data (Uint8 array)
-			qindex = findAllOccurrences(x.result, []string{`EDV Stream:`})[`EDV Stream:`][0]
-			offset = len([]byte(`EDV Stream:`)) + 1
-		case "request":
-			// Find the start of the Stream data (Uint8 array)
-			qindex = findAllOccurrences(x.result, []string{`Response:`})[`Response:`][0]
-			offset = len([]byte(`Response:`)) + 1
-		default:
-			qindex = 0
-			offset = 0
+	config = config.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, config.Path, config.Args...)
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("mercury client %s. %w", getTimeoutReason(ctxErr), withStderr(ctxErr, stderr.Bytes()))
 		}
+		return nil, fmt.Errorf("error executing mercury client. %w", withStderr(err, stderr.Bytes()))
+	}
+	out := adapterResponse{}
+	if err := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &out); err != nil {
+		return nil, fmt.Errorf("invalid response from mercury client. %w", withStderr(err, stderr.Bytes()))
+	}
+	return out.toResponse()
+}
 
-		// Preceding console logs and EDV Document `content``
-		qcontent = x.result[0 : qindex-1]
+func (c Config) withDefaults() Config {
+	if strings.TrimSpace(c.Path) == "" {
+		c.Path = DefaultPath
+	}
+	if c.Args == nil {
+		c.Args = DefaultArgs
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
 
-		// HTTP Response OR EDV Document Stream data(Uint8 array)
-		qdata = x.result[qindex+offset : len(x.result)-1]
+func newAdapterRequest(id string, operation string, target string, options RequestOptions) adapterRequest {
+	return adapterRequest{
+		ID:        id,
+		Operation: operation,
+		Target:    target,
+		Method:    options.Method,
+		Headers:   options.Headers,
+		Body:      string(options.Body),
+		Query:     options.Query,
 	}
-	return qcontent, qdata, nil
 }
 
-/*
- * Helper Function
- * Finds the location(s) of a particular string in binary UTF-8 data
- */
-func findAllOccurrences(data []byte, searches []string) map[string][]int {
-	results := make(map[string][]int)
-	for _, search := range searches {
-		searchData := data
-		term := []byte(search)
-		for x, d := bytes.Index(searchData, term), 0; x > -1; x, d = bytes.Index(searchData, term), d+x+1 {
-			results[search] = append(results[search], x+d)
-			searchData = searchData[x+1:]
+func (out adapterResponse) toResponse() (*Response, error) {
+	if out.Error != "" {
+		return nil, errors.New(out.Error)
+	}
+	res := &Response{
+		StatusCode: out.Status,
+		Headers:    out.Headers,
+		Content:    out.Content,
+		Data:       []byte(out.Data),
+		Documents:  out.Documents,
+	}
+	if res.StatusCode == 0 {
+		res.StatusCode = http.StatusOK
+	}
+	if res.Headers == nil {
+		res.Headers = http.Header{}
+	}
+	if out.DataEncoding == "base64" {
+		data, err := base64.StdEncoding.DecodeString(out.Data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 data from mercury client. %w", err)
 		}
+		res.Data = data
+	}
+	return res, nil
+}
+
+func getTimeoutReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timed out"
+	}
+	return "cancelled"
+}
+
+func withStderr(err error, stderr []byte) error {
+	if msg := strings.TrimSpace(string(stderr)); msg != "" {
+		return fmt.Errorf("%w. %s", err, msg)
 	}
-	return results
+	return err
 }
@@ -0,0 +1,120 @@
+package mercury_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/mercury"
+)
+
+func writeAdapter(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake mercury client adapter script requires a posix shell")
+	}
+	path := filepath.Join(t.TempDir(), "mercury-client")
+	require.Nil(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		args        []string
+		timeout     time.Duration
+		operation   string
+		options     mercury.RequestOptions
+		want        *mercury.Response
+		wantErr     string
+		wantErrPart string
+	}{
+		{
+			name:      "should parse the structured response",
+			script:    `echo '{"status":201,"headers":{"Content-Type":["application/json"]},"content":{"id":"z123"},"data":"{\"foo\":\"bar\"}"}'`,
+			operation: mercury.OperationDownload,
+			want: &mercury.Response{
+				StatusCode: http.StatusCreated,
+				Headers:    http.Header{"Content-Type": []string{"application/json"}},
+				Content:    []byte(`{"id":"z123"}`),
+				Data:       []byte(`{"foo":"bar"}`),
+			},
+		},
+		{
+			name:      "should pass the request as json on stdin",
+			script:    `printf '{"dataEncoding":"base64","data":"%s"}' "$(base64 | tr -d '\n')"`,
+			operation: mercury.OperationRequest,
+			options:   mercury.RequestOptions{Method: http.MethodPost, Headers: http.Header{"X-Foo": []string{"bar"}}, Body: []byte(`hello`)},
+			want: &mercury.Response{
+				StatusCode: http.StatusOK,
+				Headers:    http.Header{},
+				Data:       []byte(`{"operation":"request","target":"https://foo.com","method":"POST","headers":{"X-Foo":["bar"]},"body":"hello"}`),
+			},
+		},
+		{
+			name:      "should pass the configured arguments",
+			script:    `printf '{"data":"%s"}' "$*"`,
+			args:      []string{"--json", "--profile", "test"},
+			operation: mercury.OperationRequest,
+			want:      &mercury.Response{StatusCode: http.StatusOK, Headers: http.Header{}, Data: []byte(`--json --profile test`)},
+		},
+		{
+			name:      "should return the adapter error",
+			script:    `echo '{"status":0,"error":"capability expired"}'`,
+			operation: mercury.OperationRequest,
+			wantErr:   "capability expired",
+		},
+		{
+			name:        "should include stderr when the adapter fails",
+			script:      "echo 'key not found' >&2; exit 3",
+			operation:   mercury.OperationRequest,
+			wantErrPart: "error executing mercury client. exit status 3. key not found",
+		},
+		{
+			name:        "should error on unstructured output",
+			script:      `echo "Response: foo"`,
+			operation:   mercury.OperationRequest,
+			wantErrPart: "invalid response from mercury client",
+		},
+		{
+			name:        "should kill the adapter on timeout",
+			script:      "sleep 10",
+			timeout:     100 * time.Millisecond,
+			operation:   mercury.OperationRequest,
+			wantErrPart: "mercury client timed out",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := mercury.Config{Path: writeAdapter(t, tt.script), Args: tt.args, Timeout: tt.timeout}
+			startTime := time.Now()
+			got, err := mercury.Request(context.Background(), config, tt.operation, "https://foo.com", tt.options)
+			assert.Less(t, time.Since(startTime), 5*time.Second)
+			if tt.wantErr != "" || tt.wantErrPart != "" {
+				require.NotNil(t, err)
+				if tt.wantErr != "" {
+					assert.Equal(t, tt.wantErr, err.Error())
+				}
+				assert.Contains(t, err.Error(), tt.wantErrPart)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("should kill the adapter when the context is cancelled", func(t *testing.T) {
+		config := mercury.Config{Path: writeAdapter(t, "sleep 10")}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := mercury.Request(ctx, config, mercury.OperationRequest, "https://foo.com", mercury.RequestOptions{})
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "mercury client timed out")
+	})
+}
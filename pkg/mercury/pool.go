@@ -0,0 +1,385 @@
+package mercury
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// OperationPing is used by the pool to health check the adapter processes
+	OperationPing = "ping"
+)
+
+const (
+	DefaultPoolSize            = 2
+	DefaultMaxConcurrency      = 8
+	DefaultHealthCheckInterval = 30 * time.Second
+)
+
+var DefaultDaemonArgs = []string{"--json", "--daemon"}
+
+// maximum size of a single response line written by the adapter
+const maxResponseSize = 64 * 1024 * 1024
+
+// maximum size of the stderr output kept for error messages
+const maxStderrSize = 4 * 1024
+
+// ErrPoolClosed is returned by the requests made after the pool is closed
+var ErrPoolClosed = errors.New("mercury client pool closed")
+
+// PoolConfig of the long-lived Mercury Client Adapter processes
+type PoolConfig struct {
+	Config
+	// Size is the number of adapter processes. Defaults to 2
+	Size int
+	// MaxConcurrency is the maximum number of in-flight requests across all the processes. Defaults to 8
+	MaxConcurrency int
+	// HealthCheckInterval between the pings of the adapter processes. Defaults to 30 seconds
+	HealthCheckInterval time.Duration
+}
+
+/*
+ * Pool keeps long-lived Mercury Client Adapter processes running in daemon mode.
+ *
+ * Each process reads newline delimited JSON requests from its stdin and writes newline delimited JSON responses to its stdout.
+ * Requests and responses use the same format as the single shot adapter with an additional `id` field to correlate them,
+ * so a process can have multiple requests in-flight.
+ *
+ *		> { "id": "1", "operation": "request", "target": "https://...", "method": "GET" }
+ *		< { "id": "1", "status": 200, "data": "..." }
+ *
+ * Processes are started lazily, pinged periodically with the `ping` operation and restarted when they exit or stop responding.
+ */
+type Pool struct {
+	config    PoolConfig
+	sem       chan struct{}
+	mu        sync.Mutex
+	processes []*process
+	next      int
+	closed    bool
+	done      chan struct{}
+	ids       atomic.Uint64
+}
+
+// NewPool returns a new pool of adapter processes. Close must be called to stop the processes
+func NewPool(config PoolConfig) *Pool {
+	if len(config.Args) == 0 {
+		config.Args = DefaultDaemonArgs
+	}
+	config.Config = config.Config.withDefaults()
+	if config.Size <= 0 {
+		config.Size = DefaultPoolSize
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = DefaultMaxConcurrency
+	}
+	if config.HealthCheckInterval <= 0 {
+		config.HealthCheckInterval = DefaultHealthCheckInterval
+	}
+	p := &Pool{
+		config:    config,
+		sem:       make(chan struct{}, config.MaxConcurrency),
+		processes: make([]*process, config.Size),
+		done:      make(chan struct{}),
+	}
+	go p.healthCheck()
+	return p
+}
+
+// Request executes the operation using one of the adapter processes of the pool. See Request for the details of the operation
+func (p *Pool) Request(ctx context.Context, operation string, target string, options RequestOptions) (*Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("mercury client %s waiting for an adapter process. %w", getTimeoutReason(ctx.Err()), ctx.Err())
+	case <-p.done:
+		return nil, ErrPoolClosed
+	}
+	proc, err := p.get()
+	if err != nil {
+		return nil, err
+	}
+	out, err := proc.do(ctx, newAdapterRequest(p.nextID(), operation, target, options))
+	if err != nil {
+		return nil, err
+	}
+	return out.toResponse()
+}
+
+// Close stops all the adapter processes of the pool
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	processes := p.processes
+	p.processes = make([]*process, len(processes))
+	p.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, proc := range processes {
+		if proc == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(proc *process) {
+			defer wg.Done()
+			proc.stop()
+		}(proc)
+	}
+	wg.Wait()
+	return nil
+}
+
+// get returns the next running process of the pool in round robin order, (re)starting it when required
+func (p *Pool) get() (*process, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+	index := p.next
+	p.next = (p.next + 1) % len(p.processes)
+	proc := p.processes[index]
+	if proc != nil && proc.alive() {
+		return proc, nil
+	}
+	proc, err := startProcess(p.config.Config)
+	if err != nil {
+		return nil, err
+	}
+	p.processes[index] = proc
+	return proc, nil
+}
+
+func (p *Pool) nextID() string {
+	return strconv.FormatUint(p.ids.Add(1), 10)
+}
+
+func (p *Pool) healthCheck() {
+	ticker := time.NewTicker(p.config.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			processes := append([]*process{}, p.processes...)
+			p.mu.Unlock()
+			for index, proc := range processes {
+				if proc == nil {
+					continue
+				}
+				if proc.alive() && p.ping(proc) == nil {
+					continue
+				}
+				proc.stop()
+				p.restart(index, proc)
+			}
+		}
+	}
+}
+
+func (p *Pool) ping(proc *process) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+	defer cancel()
+	out, err := proc.do(ctx, adapterRequest{ID: p.nextID(), Operation: OperationPing})
+	if err != nil {
+		return err
+	}
+	if out.Error != "" {
+		return errors.New(out.Error)
+	}
+	return nil
+}
+
+// restart replaces the unhealthy process, unless it was already replaced by a request
+func (p *Pool) restart(index int, unhealthy *process) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.processes[index] != unhealthy {
+		return
+	}
+	proc, err := startProcess(p.config.Config)
+	if err != nil {
+		p.processes[index] = nil
+		return
+	}
+	p.processes[index] = proc
+}
+
+// process is a single long-lived adapter process
+type process struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[string]chan adapterResponse
+	stderr  *limitedBuffer
+	exited  chan struct{}
+	err     error
+}
+
+func startProcess(config Config) (*process, error) {
+	cmd := exec.Command(config.Path, config.Args...)
+	cmd.WaitDelay = time.Second
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error starting mercury client. %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error starting mercury client. %w", err)
+	}
+	proc := &process{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: map[string]chan adapterResponse{},
+		stderr:  &limitedBuffer{limit: maxStderrSize},
+		exited:  make(chan struct{}),
+	}
+	cmd.Stderr = proc.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting mercury client. %w", err)
+	}
+	go proc.read(stdout)
+	return proc, nil
+}
+
+// read dispatches the responses of the process to the pending requests until the process exits
+func (proc *process) read(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxResponseSize)
+	var err error
+	for scanner.Scan() {
+		out := adapterResponse{}
+		if err = json.Unmarshal(scanner.Bytes(), &out); err != nil {
+			err = fmt.Errorf("invalid response from mercury client. %w", err)
+			break
+		}
+		proc.mu.Lock()
+		ch, ok := proc.pending[out.ID]
+		delete(proc.pending, out.ID)
+		proc.mu.Unlock()
+		if ok {
+			ch <- out
+		}
+	}
+	if err == nil {
+		err = scanner.Err()
+	}
+	_ = proc.cmd.Process.Kill()
+	waitErr := proc.cmd.Wait()
+	if err == nil {
+		err = waitErr
+	}
+	if err == nil {
+		err = errors.New("mercury client exited")
+	}
+	proc.mu.Lock()
+	proc.err = fmt.Errorf("error executing mercury client. %w", withStderr(err, proc.stderr.Bytes()))
+	proc.pending = map[string]chan adapterResponse{}
+	proc.mu.Unlock()
+	close(proc.exited)
+}
+
+func (proc *process) do(ctx context.Context, req adapterRequest) (adapterResponse, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return adapterResponse{}, fmt.Errorf("error encoding mercury client request. %w", err)
+	}
+	ch := make(chan adapterResponse, 1)
+	proc.mu.Lock()
+	if proc.err != nil {
+		proc.mu.Unlock()
+		return adapterResponse{}, proc.err
+	}
+	proc.pending[req.ID] = ch
+	proc.mu.Unlock()
+	proc.writeMu.Lock()
+	_, err = proc.stdin.Write(append(input, '\n'))
+	proc.writeMu.Unlock()
+	if err != nil {
+		proc.forget(req.ID)
+		return adapterResponse{}, fmt.Errorf("error writing to mercury client. %w", err)
+	}
+	select {
+	case out := <-ch:
+		return out, nil
+	case <-proc.exited:
+		return adapterResponse{}, proc.exitErr()
+	case <-ctx.Done():
+		proc.forget(req.ID)
+		return adapterResponse{}, fmt.Errorf("mercury client %s. %w", getTimeoutReason(ctx.Err()), ctx.Err())
+	}
+}
+
+func (proc *process) forget(id string) {
+	proc.mu.Lock()
+	delete(proc.pending, id)
+	proc.mu.Unlock()
+}
+
+func (proc *process) alive() bool {
+	select {
+	case <-proc.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+func (proc *process) exitErr() error {
+	proc.mu.Lock()
+	defer proc.mu.Unlock()
+	return proc.err
+}
+
+// stop closes the stdin of the process, so that it can exit gracefully, and kills it if it doesn't exit in time
+func (proc *process) stop() {
+	_ = proc.stdin.Close()
+	select {
+	case <-proc.exited:
+	case <-time.After(2 * time.Second):
+		_ = proc.cmd.Process.Kill()
+		<-proc.exited
+	}
+}
+
+// limitedBuffer keeps the last bytes written to it
+type limitedBuffer struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.limit {
+		b.buf = b.buf[len(b.buf)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte{}, b.buf...)
+}
@@ -0,0 +1,88 @@
+package mercury_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/mercury"
+)
+
+// fakeDaemon answers every request with its own pid, so that the tests can tell the processes apart
+const fakeDaemon = `[ "$*" = "--json --daemon" ] || { echo "unexpected args $*" >&2; exit 2; }
+while IFS= read -r line; do
+  id=$(printf '%s' "$line" | sed -n 's/.*"id":"\([^"]*\)".*/\1/p')
+  case "$line" in
+    *'"operation":"ping"'*) [ -n "$NO_PING" ] || printf '{"id":"%s"}\n' "$id" ;;
+    *crash*) echo "adapter crashed" >&2; exit 1 ;;
+    *slow*) sleep 0.3; printf '{"id":"%s","data":"%s"}\n' "$id" "$$" ;;
+    *) printf '{"id":"%s","data":"%s"}\n' "$id" "$$" ;;
+  esac
+done
+`
+
+func poolRequest(t *testing.T, pool *mercury.Pool, target string) string {
+	t.Helper()
+	res, err := pool.Request(context.Background(), mercury.OperationRequest, target, mercury.RequestOptions{})
+	require.Nil(t, err)
+	return string(res.Data)
+}
+
+func TestPool(t *testing.T) {
+	t.Run("should reuse the adapter process", func(t *testing.T) {
+		pool := mercury.NewPool(mercury.PoolConfig{Config: mercury.Config{Path: writeAdapter(t, fakeDaemon)}, Size: 1})
+		defer pool.Close()
+		first := poolRequest(t, pool, "https://foo.com/a")
+		assert.NotEmpty(t, first)
+		assert.Equal(t, first, poolRequest(t, pool, "https://foo.com/b"))
+	})
+	t.Run("should restart the adapter process after it exits", func(t *testing.T) {
+		pool := mercury.NewPool(mercury.PoolConfig{Config: mercury.Config{Path: writeAdapter(t, fakeDaemon)}, Size: 1})
+		defer pool.Close()
+		first := poolRequest(t, pool, "https://foo.com/a")
+		_, err := pool.Request(context.Background(), mercury.OperationRequest, "https://foo.com/crash", mercury.RequestOptions{})
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "adapter crashed")
+		second := poolRequest(t, pool, "https://foo.com/a")
+		assert.NotEmpty(t, second)
+		assert.NotEqual(t, first, second)
+	})
+	t.Run("should restart the unresponsive adapter process", func(t *testing.T) {
+		t.Setenv("NO_PING", "true")
+		pool := mercury.NewPool(mercury.PoolConfig{
+			Config:              mercury.Config{Path: writeAdapter(t, fakeDaemon), Timeout: 200 * time.Millisecond},
+			Size:                1,
+			HealthCheckInterval: 100 * time.Millisecond,
+		})
+		defer pool.Close()
+		first := poolRequest(t, pool, "https://foo.com/a")
+		require.Eventually(t, func() bool {
+			return poolRequest(t, pool, "https://foo.com/a") != first
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+	t.Run("should bound the concurrent requests", func(t *testing.T) {
+		pool := mercury.NewPool(mercury.PoolConfig{Config: mercury.Config{Path: writeAdapter(t, fakeDaemon)}, Size: 3, MaxConcurrency: 1})
+		defer pool.Close()
+		startTime := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				poolRequest(t, pool, "https://foo.com/slow")
+			}()
+		}
+		wg.Wait()
+		assert.GreaterOrEqual(t, time.Since(startTime), 900*time.Millisecond)
+	})
+	t.Run("should error after the pool is closed", func(t *testing.T) {
+		pool := mercury.NewPool(mercury.PoolConfig{Config: mercury.Config{Path: writeAdapter(t, fakeDaemon)}})
+		poolRequest(t, pool, "https://foo.com/a")
+		require.Nil(t, pool.Close())
+		_, err := pool.Request(context.Background(), mercury.OperationRequest, "https://foo.com/a", mercury.RequestOptions{})
+		assert.Equal(t, mercury.ErrPoolClosed, err)
+	})
+}
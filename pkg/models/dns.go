@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// DNSOverride is the static address of the host, similar to the curl --resolve option. Empty port matches all the ports of the host
+type DNSOverride struct {
+	Host string `json:"host"`
+	Port string `json:"port,omitempty"`
+	IP   string `json:"ip"`
+}
+
+// GetDNSServerAddress returns the address of the custom dns server. Port 53 is used when the port is not specified
+func GetDNSServerAddress(server string) (string, error) {
+	server = strings.TrimSpace(server)
+	if host, port, err := net.SplitHostPort(server); err == nil {
+		if net.ParseIP(host) == nil || port == "" {
+			return "", fmt.Errorf("invalid dns server %s", server)
+		}
+		return server, nil
+	}
+	if net.ParseIP(strings.Trim(server, "[]")) == nil {
+		return "", fmt.Errorf("invalid dns server %s", server)
+	}
+	return net.JoinHostPort(strings.Trim(server, "[]"), "53"), nil
+}
+
+// FindDNSOverride returns the ip address of the first dns override matching the host and port
+func FindDNSOverride(overrides []DNSOverride, host string, port string) (string, bool) {
+	for _, override := range overrides {
+		if strings.EqualFold(strings.TrimSpace(override.Host), host) && (override.Port == "" || override.Port == port) {
+			return strings.TrimSpace(override.IP), true
+		}
+	}
+	return "", false
+}
+
+func validateDNSSettings(overrides []DNSOverride, server string) error {
+	for _, override := range overrides {
+		if strings.TrimSpace(override.Host) == "" {
+			return fmt.Errorf("invalid or empty dns override host for ip %s", override.IP)
+		}
+		if net.ParseIP(strings.TrimSpace(override.IP)) == nil {
+			return fmt.Errorf("invalid dns override ip %s for host %s", override.IP, override.Host)
+		}
+	}
+	if server != "" {
+		if _, err := GetDNSServerAddress(server); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// HostProfile is the authentication and header profile used for the requests to the urls starting with the URLPrefix
+type HostProfile struct {
+	Name      string
+	URLPrefix string
+	Settings  InfinitySettings
+}
+
+/*
+ * Host profiles are defined in the jsonData as an ordered list
+ *
+ *		"hostProfiles": [
+ *			{ "name": "api-a", "urlPrefix": "https://api-a.example.com/", "auth_method": "bearerToken", "httpHeaderName1": "X-Tenant" },
+ *			{ "name": "api-b", "urlPrefix": "https://api-b.example.com/", "auth_method": "apiKey", "apiKeyKey": "key", "apiKeyType": "query" }
+ *		]
+ *
+ * A profile inherits the datasource level jsonData fields such as the timeout, tls and proxy settings and overrides any of them.
+ * The profiles don't inherit the authentication, the custom headers and secure query fields or any of the secure fields of the datasource,
+ * so that the credentials of the datasource are never sent to the hosts of the profiles.
+ * Secure fields of the profile are stored in the secureJsonData with the `hostProfile<N>.` prefix where N is the 1 based
+ * index of the profile, for example `hostProfile1.bearerToken` or `hostProfile2.httpHeaderValue1`.
+ */
+func loadHostProfiles(config backend.DataSourceInstanceSettings) ([]HostProfile, error) {
+	var profiles []HostProfile
+	if config.JSONData == nil {
+		return profiles, nil
+	}
+	jsonData := map[string]any{}
+	if err := json.Unmarshal(config.JSONData, &jsonData); err != nil {
+		return profiles, err
+	}
+	rawProfiles, ok := jsonData["hostProfiles"].([]any)
+	if !ok {
+		return profiles, nil
+	}
+	for i, rawProfile := range rawProfiles {
+		profileJSON, ok := rawProfile.(map[string]any)
+		if !ok {
+			return profiles, fmt.Errorf("invalid host profile %d", i+1)
+		}
+		profile, err := loadHostProfile(config, jsonData, profileJSON, i+1)
+		if err != nil {
+			return profiles, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+func loadHostProfile(config backend.DataSourceInstanceSettings, jsonData map[string]any, profileJSON map[string]any, index int) (HostProfile, error) {
+	profile := HostProfile{}
+	profile.Name, _ = profileJSON["name"].(string)
+	profile.URLPrefix, _ = profileJSON["urlPrefix"].(string)
+	if profile.Name == "" {
+		profile.Name = fmt.Sprintf("%d", index)
+	}
+	profileJSONData := map[string]any{}
+	for key, value := range jsonData {
+		if key == "hostProfiles" || key == "auth_method" || key == "oauthPassThru" || isProfileSpecificKey(key, "httpHeaderName", "secureQueryName") {
+			continue
+		}
+		profileJSONData[key] = value
+	}
+	config.BasicAuthEnabled = false
+	config.BasicAuthUser = ""
+	for key, value := range profileJSON {
+		switch key {
+		case "name", "urlPrefix":
+		case "basicAuth":
+			config.BasicAuthEnabled, _ = value.(bool)
+		case "basicAuthUser":
+			config.BasicAuthUser, _ = value.(string)
+		default:
+			profileJSONData[key] = value
+		}
+	}
+	b, err := json.Marshal(profileJSONData)
+	if err != nil {
+		return profile, err
+	}
+	secretPrefix := fmt.Sprintf("hostProfile%d.", index)
+	secrets := map[string]string{}
+	for key, value := range config.DecryptedSecureJSONData {
+		if strings.HasPrefix(key, secretPrefix) {
+			secrets[strings.TrimPrefix(key, secretPrefix)] = value
+		}
+	}
+	config.JSONData = b
+	config.DecryptedSecureJSONData = secrets
+	profile.Settings, err = loadSettings(config)
+	if err != nil {
+		return profile, fmt.Errorf("invalid host profile %s. %w", profile.Name, err)
+	}
+	return profile, nil
+}
+
+func isProfileSpecificKey(key string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestLoadSettingsWithHostProfiles(t *testing.T) {
+	settings, err := models.LoadSettings(backend.DataSourceInstanceSettings{
+		BasicAuthEnabled: true,
+		BasicAuthUser:    "base-user",
+		JSONData: []byte(`{
+			"auth_method": "basicAuth",
+			"timeoutInSeconds": 30,
+			"tlsSkipVerify": true,
+			"httpHeaderName1": "X-Base",
+			"hostProfiles": [
+				{ "name": "a", "urlPrefix": "https://a.example.com/", "auth_method": "bearerToken" },
+				{ "name": "b", "urlPrefix": "https://b.example.com/" }
+			]
+		}`),
+		DecryptedSecureJSONData: map[string]string{
+			"basicAuthPassword":        "base-password",
+			"bearerToken":              "base-token",
+			"httpHeaderValue1":         "base",
+			"hostProfile1.bearerToken": "token-a",
+		},
+	})
+	require.Nil(t, err)
+	require.Len(t, settings.HostProfiles, 2)
+	t.Run("should inherit the non-secure settings of the datasource", func(t *testing.T) {
+		for _, profile := range settings.HostProfiles {
+			assert.Equal(t, int64(30), profile.Settings.TimeoutInSeconds)
+			assert.True(t, profile.Settings.InsecureSkipVerify)
+		}
+	})
+	t.Run("should use the secrets of the profile", func(t *testing.T) {
+		assert.Equal(t, models.AuthenticationMethodBearerToken, settings.HostProfiles[0].Settings.AuthenticationMethod)
+		assert.Equal(t, "token-a", settings.HostProfiles[0].Settings.BearerToken)
+	})
+	t.Run("should not inherit the authentication, headers and secrets of the datasource", func(t *testing.T) {
+		profile := settings.HostProfiles[1].Settings
+		assert.Equal(t, models.AuthenticationMethodNone, profile.AuthenticationMethod)
+		assert.False(t, profile.BasicAuthEnabled)
+		assert.Empty(t, profile.UserName)
+		assert.Empty(t, profile.Password)
+		assert.Empty(t, profile.BearerToken)
+		assert.Empty(t, profile.CustomHeaders)
+	})
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// GetProxyURL parses the proxy url. Supported schemes are http, https, socks5 and socks5h
+func GetProxyURL(proxyUrl string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(proxyUrl))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %s", proxyUrl)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u, nil
+	default:
+		return nil, fmt.Errorf("invalid proxy url scheme %s. supported schemes are http, https, socks5 and socks5h", u.Scheme)
+	}
+}
+
+// NoProxyRule is a host which must be connected directly instead of via proxy
+type NoProxyRule struct {
+	// Host is the host name. Host names starting with `.` or `*.` match the sub domains as well
+	Host string
+	// Port is the optional port of the host
+	Port string
+	// Network is the ip range of the CIDR rules
+	Network *net.IPNet
+}
+
+// GetNoProxyRules parses the no proxy list. Each entry can be a host name (foo.com), a domain suffix (.foo.com or *.foo.com),
+// a host with port (foo.com:8080), an ip address or a CIDR range (10.0.0.0/8). `*` bypasses the proxy for all the hosts
+func GetNoProxyRules(entries []string) ([]NoProxyRule, error) {
+	rules := []NoProxyRule{}
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid no proxy entry %s", entry)
+			}
+			rules = append(rules, NoProxyRule{Network: network})
+			continue
+		}
+		rule := NoProxyRule{Host: entry}
+		if host, port, err := net.SplitHostPort(entry); err == nil {
+			rule.Host, rule.Port = host, port
+		}
+		rule.Host = strings.TrimPrefix(rule.Host, "*.")
+		if strings.HasPrefix(entry, "*.") {
+			rule.Host = "." + rule.Host
+		}
+		if rule.Host == "" || strings.ContainsAny(rule.Host, " /?#@") {
+			return nil, fmt.Errorf("invalid no proxy entry %s", entry)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Match returns true when the host (with optional port) matches the rule
+func (r NoProxyRule) Match(hostPort string) bool {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host = hostPort
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if r.Network != nil {
+		ip := net.ParseIP(host)
+		return ip != nil && r.Network.Contains(ip)
+	}
+	if r.Port != "" && r.Port != port {
+		return false
+	}
+	switch {
+	case r.Host == "*":
+		return true
+	case strings.HasPrefix(r.Host, "."):
+		return host == strings.TrimPrefix(r.Host, ".") || strings.HasSuffix(host, r.Host)
+	default:
+		return host == r.Host
+	}
+}
@@ -0,0 +1,48 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestGetNoProxyRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		noProxy   []string
+		host      string
+		wantMatch bool
+		wantErr   bool
+	}{
+		{name: "exact host", noProxy: []string{"foo.com"}, host: "foo.com", wantMatch: true},
+		{name: "exact host with port", noProxy: []string{"foo.com"}, host: "foo.com:8080", wantMatch: true},
+		{name: "sub domain without wildcard", noProxy: []string{"foo.com"}, host: "api.foo.com"},
+		{name: "domain suffix", noProxy: []string{".foo.com"}, host: "api.foo.com", wantMatch: true},
+		{name: "wildcard domain", noProxy: []string{"*.foo.com"}, host: "foo.com", wantMatch: true},
+		{name: "wildcard domain should not match other domains", noProxy: []string{"*.foo.com"}, host: "barfoo.com"},
+		{name: "host with port", noProxy: []string{"foo.com:8080"}, host: "foo.com:8080", wantMatch: true},
+		{name: "host with different port", noProxy: []string{"foo.com:8080"}, host: "foo.com:9090"},
+		{name: "cidr", noProxy: []string{"10.0.0.0/8"}, host: "10.1.2.3:443", wantMatch: true},
+		{name: "cidr with other ip", noProxy: []string{"10.0.0.0/8"}, host: "192.168.1.1"},
+		{name: "ipv6 cidr", noProxy: []string{"fd00::/8"}, host: "[fd00::1]:443", wantMatch: true},
+		{name: "all hosts", noProxy: []string{"*"}, host: "foo.com", wantMatch: true},
+		{name: "invalid cidr", noProxy: []string{"10.0.0.0/99"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := models.GetNoProxyRules(tt.noProxy)
+			if tt.wantErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			match := false
+			for _, rule := range rules {
+				match = match || rule.Match(tt.host)
+			}
+			assert.Equal(t, tt.wantMatch, match)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 )
 
 type QueryType string
@@ -38,11 +39,15 @@ const (
 type PaginationMode string
 
 const (
-	PaginationModeNone   PaginationMode = "none"
-	PaginationModeOffset PaginationMode = "offset"
-	PaginationModePage   PaginationMode = "page"
-	PaginationModeCursor PaginationMode = "cursor"
-	PaginationModeList   PaginationMode = "list"
+	PaginationModeNone     PaginationMode = "none"
+	PaginationModeOffset   PaginationMode = "offset"
+	PaginationModePage     PaginationMode = "page"
+	PaginationModeCursor   PaginationMode = "cursor"
+	PaginationModeList     PaginationMode = "list"
+	PaginationModeNextLink PaginationMode = "next_link"
+	PaginationModeRelay    PaginationMode = "relay"
+	// time window pagination splits the time range of the query into windows of pagination_time_window_duration
+	PaginationModeTimeWindow PaginationMode = "time_window"
 )
 
 type PaginationParamType string
@@ -55,6 +60,14 @@ const (
 	PaginationParamTypeReplace  PaginationParamType = "replace"
 )
 
+// PaginationCursorSource is where the cursor of the cursor pagination is extracted from
+type PaginationCursorSource string
+
+const (
+	PaginationCursorSourceBody   PaginationCursorSource = "body"
+	PaginationCursorSourceHeader PaginationCursorSource = "header"
+)
+
 type Transformation string
 
 const (
@@ -89,7 +102,7 @@ type Query struct {
 	RefID                              string                 `json:"refId"`
 	Type                               QueryType              `json:"type"`   // 'json' | 'json-backend' | 'csv' | 'tsv' | 'xml' | 'graphql' | 'html' | 'uql' | 'groq' | 'series' | 'global' | 'google-sheets'
 	Format                             string                 `json:"format"` // 'table' | 'timeseries' | 'logs' | 'dataframe' | 'as-is' | 'node-graph-nodes' | 'node-graph-edges'
-	Source                             string                 `json:"source"` // 'url' | 'inline' | 'azure-blob' | 'reference' | 'random-walk' | 'expression'
+	Source                             string                 `json:"source"` // 'url' | 'inline' | 'azure-blob' | 'edv' | 'reference' | 'random-walk' | 'expression'
 	RefName                            string                 `json:"referenceName,omitempty"`
 	URL                                string                 `json:"url"`
 	URLOptions                         URLOptions             `json:"url_options"`
@@ -118,6 +131,7 @@ type Query struct {
 	SheetRange                         string                 `json:"range,omitempty"`
 	AzBlobContainerName                string                 `json:"azContainerName,omitempty"`
 	AzBlobName                         string                 `json:"azBlobName,omitempty"`
+	EDVOptions                         *EDVOptions            `json:"edv_options,omitempty"`
 	PageMode                           PaginationMode         `json:"pagination_mode,omitempty"`
 	PageMaxPages                       int                    `json:"pagination_max_pages,omitempty"`
 	PageParamSizeFieldName             string                 `json:"pagination_param_size_field_name,omitempty"`
@@ -132,12 +146,37 @@ type Query struct {
 	PageParamCursorFieldName           string                 `json:"pagination_param_cursor_field_name,omitempty"`
 	PageParamCursorFieldType           PaginationParamType    `json:"pagination_param_cursor_field_type,omitempty"`
 	PageParamCursorFieldExtractionPath string                 `json:"pagination_param_cursor_extraction_path,omitempty"`
+	PageParamCursorExtractionSource    PaginationCursorSource `json:"pagination_param_cursor_extraction_source,omitempty"`
 	PageParamListFieldName             string                 `json:"pagination_param_list_field_name,omitempty"`
 	PageParamListFieldType             PaginationParamType    `json:"pagination_param_list_field_type,omitempty"`
 	PageParamListFieldValue            string                 `json:"pagination_param_list_value,omitempty"`
+	PageParamNextLinkExtractionPath    string                 `json:"pagination_param_next_link_extraction_path,omitempty"`
+	PageParamHasMoreExtractionPath     string                 `json:"pagination_param_has_more_extraction_path,omitempty"`
+	PageParamTotalExtractionPath       string                 `json:"pagination_param_total_extraction_path,omitempty"`
+	PageStopOnEmptyPage                bool                   `json:"pagination_stop_on_empty_page,omitempty"`
+	PageStopOnPartialPage              bool                   `json:"pagination_stop_on_partial_page,omitempty"`
+	PageStopOnDuplicatePage            bool                   `json:"pagination_stop_on_duplicate_page,omitempty"`
+	PageMaxRows                        int                    `json:"pagination_max_rows,omitempty"`
+	PageParallelism                    int                    `json:"pagination_parallelism,omitempty"`
+	PagePartialResults                 bool                   `json:"pagination_partial_results,omitempty"`
+	PageParamStartFieldName            string                 `json:"pagination_param_start_field_name,omitempty"`
+	PageParamStartFieldType            PaginationParamType    `json:"pagination_param_start_field_type,omitempty"`
+	PageParamEndFieldName              string                 `json:"pagination_param_end_field_name,omitempty"`
+	PageParamEndFieldType              PaginationParamType    `json:"pagination_param_end_field_type,omitempty"`
+	PageTimeWindowDuration             string                 `json:"pagination_time_window_duration,omitempty"`
+	PageTimeWindowFormat               TimeWindowFormat       `json:"pagination_time_window_format,omitempty"`
+	TimeRange                          backend.TimeRange      `json:"-"`
 	Transformations                    []TransformationItem   `json:"transformations,omitempty"`
 }
 
+type TimeWindowFormat string
+
+const (
+	TimeWindowFormatRFC3339 TimeWindowFormat = "rfc3339"
+	TimeWindowFormatUnix    TimeWindowFormat = "unix"
+	TimeWindowFormatUnixMs  TimeWindowFormat = "unix_ms"
+)
+
 type URLOptionKeyValuePair struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -155,6 +194,16 @@ type URLOptions struct {
 	BodyGraphQLVariables string                  `json:"body_graphql_variables"`
 }
 
+// EDVOptions of the encrypted data vault queries. The vault url is taken from the query url
+type EDVOptions struct {
+	// DocumentIDs to fetch. When empty, the documents of the vault matching the index filters are listed
+	DocumentIDs []string            `json:"document_ids,omitempty"`
+	Index       string              `json:"index,omitempty"`
+	Equals      []map[string]string `json:"equals,omitempty"`
+	Has         []string            `json:"has,omitempty"`
+	Limit       int                 `json:"limit,omitempty"`
+}
+
 type InfinityCSVOptions struct {
 	Delimiter          string `json:"delimiter"`
 	SkipEmptyLines     bool   `json:"skip_empty_lines"`
@@ -188,6 +237,27 @@ type InfinityDataOverride struct {
 	Override string   `json:"override"`
 }
 
+type paginationMaxPagesKey struct{}
+
+// WithPaginationMaxPages sets the pagination max pages of the datasource in the context, so that the max pages of the queries loaded with the context are limited by it
+func WithPaginationMaxPages(ctx context.Context, maxPages int) context.Context {
+	return context.WithValue(ctx, paginationMaxPagesKey{}, maxPages)
+}
+
+// getQueryPaginationMaxPages returns the limit of the max pages of the query, which is the pagination max pages of the datasource set in the context
+func getQueryPaginationMaxPages(ctx context.Context, query Query) int {
+	maxPages, _ := ctx.Value(paginationMaxPagesKey{}).(int)
+	return GetPaginationMaxPages(maxPages)
+}
+
+// GetPaginationMaxPages returns the pagination max pages of the datasource. Defaults to 5 pages and never exceeds the hard ceiling of 1000 pages
+func GetPaginationMaxPages(maxPages int) int {
+	if maxPages <= 0 {
+		return DefaultPaginationMaxPages
+	}
+	return min(maxPages, PaginationMaxPagesLimit)
+}
+
 func ApplyDefaultsToQuery(ctx context.Context, query Query) Query {
 	if query.Type == "" {
 		query.Type = QueryTypeJSON
@@ -249,13 +319,18 @@ func ApplyDefaultsToQuery(ctx context.Context, query Query) Query {
 	if query.ComputedColumns == nil {
 		query.ComputedColumns = []InfinityColumn{}
 	}
-	if query.Parser == InfinityParserBackend && query.Source == "url" && !(query.PageMode == "" || query.PageMode == PaginationModeNone) {
+	// the raw pages of the uql and groq queries are merged before the uql or groq query is evaluated
+	if (query.Parser == InfinityParserBackend || IsUQLOrGROQQuery(query)) && query.Source == "url" && !(query.PageMode == "" || query.PageMode == PaginationModeNone) {
 		if query.PageMode != PaginationModeNone {
 			if query.PageMaxPages <= 0 {
 				query.PageMaxPages = 1
+				// number of the time windows depends on the time range. so the time windows default to the pagination max pages of the datasource
+				if query.PageMode == PaginationModeTimeWindow {
+					query.PageMaxPages = getQueryPaginationMaxPages(ctx, query)
+				}
 			}
-			if query.PageMaxPages >= 5 {
-				query.PageMaxPages = 5
+			if maxPages := getQueryPaginationMaxPages(ctx, query); query.PageMaxPages > maxPages {
+				query.PageMaxPages = maxPages
 			}
 			if query.PageParamSizeFieldName == "" {
 				query.PageParamSizeFieldName = "limit"
@@ -296,6 +371,41 @@ func ApplyDefaultsToQuery(ctx context.Context, query Query) Query {
 			if query.PageParamCursorFieldType == "" {
 				query.PageParamCursorFieldType = PaginationParamTypeQuery
 			}
+			if query.PageParamCursorExtractionSource == "" {
+				query.PageParamCursorExtractionSource = PaginationCursorSourceBody
+			}
+		}
+		// root selector of the relay pagination is the path of the connection. for example data.repository.issues
+		if query.PageMode == PaginationModeRelay {
+			if query.PageParamCursorFieldName == "" {
+				query.PageParamCursorFieldName = "after"
+			}
+			if query.PageParamCursorFieldExtractionPath == "" {
+				query.PageParamCursorFieldExtractionPath = query.RootSelector + ".pageInfo.endCursor"
+			}
+			if query.PageParamHasMoreExtractionPath == "" {
+				query.PageParamHasMoreExtractionPath = query.RootSelector + ".pageInfo.hasNextPage"
+			}
+		}
+		if query.PageMode == PaginationModeTimeWindow {
+			if query.PageParamStartFieldName == "" {
+				query.PageParamStartFieldName = "from"
+			}
+			if query.PageParamStartFieldType == "" {
+				query.PageParamStartFieldType = PaginationParamTypeQuery
+			}
+			if query.PageParamEndFieldName == "" {
+				query.PageParamEndFieldName = "to"
+			}
+			if query.PageParamEndFieldType == "" {
+				query.PageParamEndFieldType = PaginationParamTypeQuery
+			}
+			if query.PageTimeWindowDuration == "" {
+				query.PageTimeWindowDuration = "1d"
+			}
+			if query.PageTimeWindowFormat == "" {
+				query.PageTimeWindowFormat = TimeWindowFormatRFC3339
+			}
 		}
 	}
 	for i, t := range query.Transformations {
@@ -306,6 +416,11 @@ func ApplyDefaultsToQuery(ctx context.Context, query Query) Query {
 	return query
 }
 
+// IsUQLOrGROQQuery returns true for the uql and groq query types and parsers. These queries are evaluated by the frontend on the raw response
+func IsUQLOrGROQQuery(query Query) bool {
+	return query.Type == QueryTypeUQL || query.Type == QueryTypeGROQ || query.Parser == InfinityParserUQL || query.Parser == InfinityParserGROQ
+}
+
 func LoadQuery(ctx context.Context, backendQuery backend.DataQuery, pluginContext backend.PluginContext) (Query, error) {
 	var query Query
 	err := json.Unmarshal(backendQuery.JSON, &query)
@@ -316,5 +431,25 @@ func LoadQuery(ctx context.Context, backendQuery backend.DataQuery, pluginContex
 	if query.PageMode == PaginationModeList && strings.TrimSpace(query.PageParamListFieldName) == "" {
 		return query, errors.New("pagination_param_list_field_name cannot be empty")
 	}
+	if query.PageMode == PaginationModeRelay && query.Parser == InfinityParserBackend && strings.TrimSpace(query.RootSelector) == "" {
+		return query, errors.New("root_selector cannot be empty for relay pagination. root_selector should be the path of the relay connection")
+	}
+	if query.PageMode == PaginationModeCursor && query.PageParamCursorExtractionSource == PaginationCursorSourceHeader && strings.TrimSpace(query.PageParamCursorFieldExtractionPath) == "" {
+		return query, errors.New("pagination_param_cursor_extraction_path cannot be empty for the header cursor. extraction path should be the name of the response header")
+	}
+	if query.PageMode == PaginationModeTimeWindow && (query.Parser == InfinityParserBackend || IsUQLOrGROQQuery(query)) {
+		if duration, err := gtime.ParseDuration(query.PageTimeWindowDuration); err != nil || duration <= 0 {
+			return query, fmt.Errorf("invalid pagination_time_window_duration %s. duration should be positive, for example 1h or 1d", query.PageTimeWindowDuration)
+		}
+		switch query.PageTimeWindowFormat {
+		case TimeWindowFormatRFC3339, TimeWindowFormatUnix, TimeWindowFormatUnixMs:
+		default:
+			return query, fmt.Errorf("invalid pagination_time_window_format %s. supported formats are rfc3339, unix and unix_ms", query.PageTimeWindowFormat)
+		}
+	}
+	query.TimeRange = backendQuery.TimeRange
+	if query.Source == "edv" && strings.TrimSpace(query.URL) == "" {
+		return query, errors.New("edv vault url cannot be empty")
+	}
 	return ApplyMacros(ctx, query, backendQuery.TimeRange, pluginContext)
 }
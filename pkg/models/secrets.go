@@ -0,0 +1,178 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	SecretReferencePrefixFile = "file://"
+	SecretReferencePrefixEnv  = "env:"
+)
+
+// Plugin config environment variables of the secret references. Grafana passes the `[plugin.yesoreyeram-infinity-datasource]`
+// section of the grafana.ini to the plugin as `GF_PLUGIN_` prefixed environment variables
+const (
+	EnvSecretReferenceAllowedPaths       = "GF_PLUGIN_SECRET_REFERENCE_ALLOWED_PATHS"
+	EnvSecretReferenceAllowedEnvPrefixes = "GF_PLUGIN_SECRET_REFERENCE_ALLOWED_ENV_PREFIXES"
+	EnvSecretReferenceRefreshInterval    = "GF_PLUGIN_SECRET_REFERENCE_REFRESH_INTERVAL"
+)
+
+const DefaultSecretReferenceRefreshInterval = 5 * time.Minute
+
+// maximum size of the secret files
+const maxSecretFileSize = 1024 * 1024
+
+// SecretReferenceConfig is the plugin level allow-list of the secret references.
+// Secret references are not resolved unless the allowed paths or the allowed env prefixes are configured
+type SecretReferenceConfig struct {
+	// AllowedPaths are the directories from which the `file://` references can be read
+	AllowedPaths []string
+	// AllowedEnvPrefixes are the prefixes of the environment variables which can be used with `env:` references
+	AllowedEnvPrefixes []string
+	// RefreshInterval between the re-reads of the secret references. Zero disables the refresh
+	RefreshInterval time.Duration
+}
+
+// GetSecretReferenceConfig returns the secret reference config from the plugin config environment variables
+func GetSecretReferenceConfig() SecretReferenceConfig {
+	config := SecretReferenceConfig{
+		AllowedPaths:       splitConfigList(os.Getenv(EnvSecretReferenceAllowedPaths)),
+		AllowedEnvPrefixes: splitConfigList(os.Getenv(EnvSecretReferenceAllowedEnvPrefixes)),
+		RefreshInterval:    DefaultSecretReferenceRefreshInterval,
+	}
+	if val := strings.TrimSpace(os.Getenv(EnvSecretReferenceRefreshInterval)); val != "" {
+		if interval, err := time.ParseDuration(val); err == nil && interval >= 0 {
+			config.RefreshInterval = interval
+		}
+	}
+	return config
+}
+
+// Enabled returns true when any of the secret reference types is allowed
+func (c SecretReferenceConfig) Enabled() bool {
+	return len(c.AllowedPaths) > 0 || len(c.AllowedEnvPrefixes) > 0
+}
+
+// HasSecretReferences returns true when any of the secure fields is a secret reference
+func HasSecretReferences(secureJSONData map[string]string) bool {
+	for _, value := range secureJSONData {
+		if isSecretReference(value) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * ResolveSecretReferences returns a copy of the secure fields with the secret references replaced by their values.
+ *
+ * Supported references:
+ *		file:///var/run/secrets/token	content of the file, without the trailing new line. The file must be inside one of the allowed paths
+ *		env:API_TOKEN					value of the environment variable. The variable name must start with one of the allowed env prefixes
+ *
+ * When the secret references are not enabled in the plugin config, the secure fields are returned as it is.
+ */
+func ResolveSecretReferences(secureJSONData map[string]string, config SecretReferenceConfig) (map[string]string, error) {
+	resolved := make(map[string]string, len(secureJSONData))
+	for key, value := range secureJSONData {
+		resolved[key] = value
+	}
+	if !config.Enabled() {
+		return resolved, nil
+	}
+	for key, value := range secureJSONData {
+		var err error
+		switch {
+		case strings.HasPrefix(value, SecretReferencePrefixFile):
+			resolved[key], err = resolveFileReference(strings.TrimPrefix(value, SecretReferencePrefixFile), config.AllowedPaths)
+		case strings.HasPrefix(value, SecretReferencePrefixEnv):
+			resolved[key], err = resolveEnvReference(strings.TrimPrefix(value, SecretReferencePrefixEnv), config.AllowedEnvPrefixes)
+		}
+		if err != nil {
+			return resolved, fmt.Errorf("error resolving secret reference of %s. %w", key, err)
+		}
+	}
+	return resolved, nil
+}
+
+func resolveFileReference(path string, allowedPaths []string) (string, error) {
+	path = filepath.Clean(path)
+	if !filepath.IsAbs(path) {
+		return "", errors.New("file path must be absolute")
+	}
+	// symlinks are resolved before the allow-list check so that they can't point outside of the allowed paths
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading secret file. %w", err)
+	}
+	if !isPathAllowed(resolvedPath, allowedPaths) {
+		return "", fmt.Errorf("file %s is not in the allowed secret reference paths", path)
+	}
+	f, err := os.Open(resolvedPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading secret file. %w", err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(io.LimitReader(f, maxSecretFileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("error reading secret file. %w", err)
+	}
+	if len(b) > maxSecretFileSize {
+		return "", errors.New("secret file is too large")
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r"), nil
+}
+
+func isPathAllowed(path string, allowedPaths []string) bool {
+	for _, allowedPath := range allowedPaths {
+		allowedPath, err := filepath.EvalSymlinks(filepath.Clean(allowedPath))
+		if err != nil || !filepath.IsAbs(allowedPath) {
+			continue
+		}
+		rel, err := filepath.Rel(allowedPath, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func resolveEnvReference(name string, allowedPrefixes []string) (string, error) {
+	name = strings.TrimSpace(name)
+	allowed := false
+	for _, prefix := range allowedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			allowed = true
+			break
+		}
+	}
+	if name == "" || !allowed {
+		return "", fmt.Errorf("environment variable %s is not in the allowed secret reference prefixes", name)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s not found", name)
+	}
+	return value, nil
+}
+
+func isSecretReference(value string) bool {
+	return strings.HasPrefix(value, SecretReferencePrefixFile) || strings.HasPrefix(value, SecretReferencePrefixEnv)
+}
+
+func splitConfigList(input string) []string {
+	out := []string{}
+	for _, item := range strings.Split(input, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
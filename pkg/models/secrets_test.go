@@ -0,0 +1,103 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestResolveSecretReferences(t *testing.T) {
+	secretsDir := t.TempDir()
+	otherDir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(secretsDir, "token"), []byte("my-token\n"), 0o600))
+	require.Nil(t, os.WriteFile(filepath.Join(otherDir, "token"), []byte("other-token"), 0o600))
+	require.Nil(t, os.Symlink(filepath.Join(otherDir, "token"), filepath.Join(secretsDir, "link")))
+	t.Setenv("INFINITY_API_TOKEN", "env-token")
+	t.Setenv("OTHER_API_TOKEN", "other-token")
+	config := models.SecretReferenceConfig{AllowedPaths: []string{secretsDir}, AllowedEnvPrefixes: []string{"INFINITY_"}}
+	tests := []struct {
+		name    string
+		input   map[string]string
+		config  models.SecretReferenceConfig
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "should not resolve the references when not enabled",
+			input:  map[string]string{"bearerToken": "env:INFINITY_API_TOKEN"},
+			config: models.SecretReferenceConfig{},
+			want:   map[string]string{"bearerToken": "env:INFINITY_API_TOKEN"},
+		},
+		{
+			name:   "should resolve file and env references",
+			input:  map[string]string{"bearerToken": "file://" + filepath.Join(secretsDir, "token"), "httpHeaderValue1": "env:INFINITY_API_TOKEN", "basicAuthPassword": "plain"},
+			config: config,
+			want:   map[string]string{"bearerToken": "my-token", "httpHeaderValue1": "env-token", "basicAuthPassword": "plain"},
+		},
+		{
+			name:    "should not resolve files outside of the allowed paths",
+			input:   map[string]string{"bearerToken": "file://" + filepath.Join(otherDir, "token")},
+			config:  config,
+			wantErr: true,
+		},
+		{
+			name:    "should not resolve files using relative paths",
+			input:   map[string]string{"bearerToken": "file://" + secretsDir + "/../" + filepath.Base(otherDir) + "/token"},
+			config:  config,
+			wantErr: true,
+		},
+		{
+			name:    "should not resolve symlinks pointing outside of the allowed paths",
+			input:   map[string]string{"bearerToken": "file://" + filepath.Join(secretsDir, "link")},
+			config:  config,
+			wantErr: true,
+		},
+		{
+			name:    "should not resolve env variables without allowed prefix",
+			input:   map[string]string{"bearerToken": "env:OTHER_API_TOKEN"},
+			config:  config,
+			wantErr: true,
+		},
+		{
+			name:    "should error when the env variable is missing",
+			input:   map[string]string{"bearerToken": "env:INFINITY_MISSING"},
+			config:  config,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := models.ResolveSecretReferences(tt.input, tt.config)
+			if tt.wantErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, models.HasSecretReferences(tt.input))
+		})
+	}
+}
+
+func TestLoadSettingsWithSecretReferences(t *testing.T) {
+	secretsDir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(secretsDir, "password"), []byte("my-password"), 0o600))
+	t.Setenv(models.EnvSecretReferenceAllowedPaths, secretsDir)
+	t.Setenv(models.EnvSecretReferenceAllowedEnvPrefixes, "INFINITY_")
+	t.Setenv("INFINITY_HEADER_VALUE", "header-value")
+	settings, err := models.LoadSettings(backend.DataSourceInstanceSettings{
+		JSONData: []byte(`{ "httpHeaderName1": "X-API-Key" }`),
+		DecryptedSecureJSONData: map[string]string{
+			"basicAuthPassword": "file://" + filepath.Join(secretsDir, "password"),
+			"httpHeaderValue1":  "env:INFINITY_HEADER_VALUE",
+		},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "my-password", settings.Password)
+	assert.Equal(t, map[string]string{"X-API-Key": "header-value"}, settings.CustomHeaders)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/textproto"
+	"net/url"
 	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -22,6 +23,8 @@ const (
 	AuthenticationMethodAWS          = "aws"
 	AuthenticationMethodZCAP         = "zcap" //variable for authentication ZCap type
 	AuthenticationMethodAzureBlob    = "azureBlob"
+	AuthenticationMethodAzureAD      = "azureAD"
+	AuthenticationMethodHMAC         = "hmac"
 )
 
 const (
@@ -61,6 +64,152 @@ type AWSSettings struct {
 	Service  string      `json:"service"`
 }
 
+type AzureADAuthType string
+
+const (
+	AzureADAuthTypeClientSecret      AzureADAuthType = "clientSecret"
+	AzureADAuthTypeClientCertificate AzureADAuthType = "clientCertificate"
+)
+
+type AzureADCloud string
+
+const (
+	AzureADCloudPublic     AzureADCloud = "AzurePublic"
+	AzureADCloudChina      AzureADCloud = "AzureChina"
+	AzureADCloudGovernment AzureADCloud = "AzureUSGovernment"
+)
+
+type AzureADSettings struct {
+	AuthType                  AzureADAuthType `json:"authType,omitempty"`
+	Cloud                     AzureADCloud    `json:"cloud,omitempty"`
+	AuthorityHost             string          `json:"authorityHost,omitempty"`
+	TenantID                  string          `json:"tenantId,omitempty"`
+	ClientID                  string          `json:"clientId,omitempty"`
+	Scopes                    []string        `json:"scopes,omitempty"`
+	ClientSecret              string
+	ClientCertificate         string
+	ClientCertificatePassword string
+}
+
+type HMACAlgorithm string
+
+const (
+	HMACAlgorithmSHA1   HMACAlgorithm = "sha1"
+	HMACAlgorithmSHA256 HMACAlgorithm = "sha256"
+	HMACAlgorithmSHA512 HMACAlgorithm = "sha512"
+)
+
+type HMACEncoding string
+
+const (
+	HMACEncodingHex    HMACEncoding = "hex"
+	HMACEncodingBase64 HMACEncoding = "base64"
+)
+
+type HMACTimestampFormat string
+
+const (
+	HMACTimestampFormatUnix      HMACTimestampFormat = "unix"
+	HMACTimestampFormatUnixMilli HMACTimestampFormat = "unix_ms"
+	HMACTimestampFormatRFC3339   HMACTimestampFormat = "rfc3339"
+	HMACTimestampFormatRFC1123   HMACTimestampFormat = "rfc1123"
+)
+
+const HMACDefaultStringToSign = "${method}\n${path}\n${timestamp}\n${bodyHash}"
+
+type HMACSettings struct {
+	// StringToSign is the template of the signed message. Supported placeholders are
+	// ${method}, ${path}, ${query}, ${uri}, ${host}, ${timestamp}, ${body}, ${bodyHash} (hex) and ${bodyHashBase64}
+	StringToSign    string              `json:"stringToSign,omitempty"`
+	Algorithm       HMACAlgorithm       `json:"algorithm,omitempty"`
+	Encoding        HMACEncoding        `json:"encoding,omitempty"`
+	SecretEncoding  string              `json:"secretEncoding,omitempty"` // '' | 'base64'
+	TimestampFormat HMACTimestampFormat `json:"timestampFormat,omitempty"`
+	TimestampHeader string              `json:"timestampHeader,omitempty"`
+	SignatureHeader string              `json:"signatureHeader,omitempty"`
+	SignaturePrefix string              `json:"signaturePrefix,omitempty"`
+	Secret          string
+}
+
+type ZCapMode string
+
+const (
+	ZCapModeMercury ZCapMode = "mercury"
+	ZCapModeNative  ZCapMode = "native"
+)
+
+type ZCapSettings struct {
+	Mode             ZCapMode `json:"mode,omitempty"`
+	KeyID            string   `json:"keyId,omitempty"`
+	Capability       string   `json:"capability,omitempty"`
+	InvocationTarget string   `json:"invocationTarget,omitempty"`
+	Action           string   `json:"action,omitempty"`
+	// AdapterPath is the path of the mercury client adapter binary. Defaults to `mercury-client`. Must be allowed by `zcap_adapter_allowed_paths` of the plugin config
+	AdapterPath string `json:"adapterPath,omitempty"`
+	// AdapterArgs are the arguments passed to the mercury client adapter binary. Require an allowed adapter path
+	AdapterArgs []string `json:"adapterArgs,omitempty"`
+	// AdapterTimeoutInSeconds is the timeout of a single mercury client adapter operation. Defaults to 60 seconds
+	AdapterTimeoutInSeconds int64 `json:"adapterTimeoutInSeconds,omitempty"`
+	// PersistentAdapter keeps a pool of long-lived mercury client adapter processes instead of starting one per request. The pool runs the same allowed adapter as the per request processes
+	PersistentAdapter bool `json:"persistentAdapter,omitempty"`
+	// AdapterPoolSize is the number of persistent adapter processes. Defaults to 2
+	AdapterPoolSize int `json:"adapterPoolSize,omitempty"`
+	// AdapterMaxConcurrency is the maximum number of in-flight requests to the persistent adapter processes. Defaults to 8
+	AdapterMaxConcurrency int `json:"adapterMaxConcurrency,omitempty"`
+	PrivateKey            string
+}
+
+// TransportSettings are the connection pool and timeout settings of the http transport shared by all the requests of the datasource instance.
+// Zero values use the defaults
+type TransportSettings struct {
+	// MaxIdleConns is the maximum number of idle connections across all the hosts. Defaults to 100
+	MaxIdleConns int `json:"maxIdleConns,omitempty"`
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept per host. Defaults to 10
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty"`
+	// IdleConnTimeoutInSeconds is the duration after which the idle connections are closed. Defaults to 90 seconds
+	IdleConnTimeoutInSeconds int `json:"idleConnTimeoutInSeconds,omitempty"`
+	// DialTimeoutInSeconds is the timeout of establishing the connections. Defaults to 30 seconds
+	DialTimeoutInSeconds int `json:"dialTimeoutInSeconds,omitempty"`
+	// TLSHandshakeTimeoutInSeconds is the timeout of the tls handshakes. Defaults to 10 seconds
+	TLSHandshakeTimeoutInSeconds int `json:"tlsHandshakeTimeoutInSeconds,omitempty"`
+	// KeepAliveInSeconds is the interval of the tcp keep-alive probes. Defaults to 30 seconds
+	KeepAliveInSeconds int `json:"keepAliveInSeconds,omitempty"`
+	// DisableKeepAlives disables the reuse of the connections between the requests
+	DisableKeepAlives bool `json:"disableKeepAlives,omitempty"`
+	// EnableHTTP2 attempts HTTP/2 for the https requests
+	EnableHTTP2 bool `json:"enableHttp2,omitempty"`
+}
+
+// RateLimit is the token bucket rate limit and the max in-flight requests cap of the urls starting with the Host.
+// Limits are shared by all the queries and pagination requests of the datasource instance
+type RateLimit struct {
+	// Host is the url prefix, similar to the allowed hosts
+	Host string `json:"host"`
+	// RequestsPerSecond is the rate of the token bucket. Zero disables the rate limit
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+	// Burst is the size of the token bucket. Defaults to 1
+	Burst int `json:"burst,omitempty"`
+	// MaxConcurrency is the maximum number of in-flight requests. Zero disables the concurrency cap
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+}
+
+// CircuitBreakerSettings are the settings of the per-host circuit breaker. Once open, the requests to the host fail fast
+// until the open duration is elapsed and then a single probe request is allowed to close the circuit again
+type CircuitBreakerSettings struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// FailureThreshold is the number of consecutive failures after which the circuit opens. Defaults to 5
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+	// OpenDurationInSeconds is the duration the circuit stays open before the probe request. Defaults to 30 seconds
+	OpenDurationInSeconds int `json:"openDurationInSeconds,omitempty"`
+}
+
+const (
+	// DefaultPaginationMaxPages is the max pages of the query when the datasource pagination max pages is not configured
+	DefaultPaginationMaxPages = 5
+	// PaginationMaxPagesLimit is the hard ceiling of the datasource pagination max pages
+	PaginationMaxPagesLimit = 1000
+)
+
 type ProxyType string
 
 const (
@@ -75,6 +224,7 @@ type InfinitySettings struct {
 	OAuth2Settings           OAuth2Settings
 	BearerToken              string
 	ZCapJsonPath             string //Field for InfinitySettings target resource
+	ZCapSettings             ZCapSettings
 	ApiKeyKey                string
 	ApiKeyType               string
 	ApiKeyValue              string
@@ -96,8 +246,26 @@ type InfinitySettings struct {
 	TLSCACert                string
 	TLSClientCert            string
 	TLSClientKey             string
+	TLSClientPKCS12          string
+	TLSClientPKCS12Password  string
+	TLSMinVersion            string
+	TLSCipherSuites          []string
+	TLSPinnedPublicKeys      []string
+	TLSPinnedHosts           []string
 	ProxyType                ProxyType
 	ProxyUrl                 string
+	ProxyUsername            string
+	ProxyPassword            string
+	ProxyCACert              string
+	NoProxy                  []string
+	DNSOverrides             []DNSOverride
+	DNSServer                string
+	TransportSettings        TransportSettings
+	RateLimits               []RateLimit
+	CircuitBreaker           CircuitBreakerSettings
+	ServeStaleOnError        bool
+	StaleMaxAgeInSeconds     int64
+	PaginationMaxPages       int
 	AllowedHosts             []string
 	EnableOpenAPI            bool
 	OpenAPIVersion           string
@@ -109,6 +277,9 @@ type InfinitySettings struct {
 	AzureBlobAccountUrl      string
 	AzureBlobAccountName     string
 	AzureBlobAccountKey      string
+	AzureADSettings          AzureADSettings
+	HMACSettings             HMACSettings
+	HostProfiles             []HostProfile
 }
 
 func (s *InfinitySettings) Validate() error {
@@ -124,19 +295,121 @@ func (s *InfinitySettings) Validate() error {
 	if s.AuthenticationMethod == AuthenticationMethodAzureBlob {
 		return nil
 	}
-	if s.AuthenticationMethod == AuthenticationMethodZCAP && (s.ZCapJsonPath == "" || !strings.HasPrefix(s.ZCapJsonPath, "https")) {
+	// scopes and allowed hosts are not required when the azure ad credential is used only for the azure blob queries
+	isAzureADBlobOnly := s.AuthenticationMethod == AuthenticationMethodAzureAD && s.AzureBlobAccountName != "" && len(s.AllowedHosts) < 1
+	if s.AuthenticationMethod == AuthenticationMethodAzureAD {
+		if s.AzureADSettings.TenantID == "" || s.AzureADSettings.ClientID == "" {
+			return errors.New("invalid or empty azure ad tenant id or client id")
+		}
+		if s.AzureADSettings.AuthType == AzureADAuthTypeClientCertificate && s.AzureADSettings.ClientCertificate == "" {
+			return errors.New("invalid or empty azure ad client certificate")
+		}
+		if s.AzureADSettings.AuthType != AzureADAuthTypeClientCertificate && s.AzureADSettings.ClientSecret == "" {
+			return errors.New("invalid or empty azure ad client secret")
+		}
+		if !isAzureADBlobOnly && len(s.AzureADSettings.Scopes) < 1 {
+			return errors.New("invalid or empty azure ad scopes")
+		}
+	}
+	if s.AuthenticationMethod == AuthenticationMethodHMAC {
+		if s.HMACSettings.Secret == "" {
+			return errors.New("invalid or empty hmac secret")
+		}
+		switch s.HMACSettings.Algorithm {
+		case HMACAlgorithmSHA1, HMACAlgorithmSHA256, HMACAlgorithmSHA512:
+		default:
+			return fmt.Errorf("invalid hmac algorithm %s", s.HMACSettings.Algorithm)
+		}
+		if s.HMACSettings.SignatureHeader == "" {
+			return errors.New("invalid or empty hmac signature header")
+		}
+	}
+	if s.AuthenticationMethod == AuthenticationMethodZCAP && s.ZCapSettings.Mode == ZCapModeNative && s.ZCapSettings.PrivateKey == "" {
+		return errors.New("invalid or empty zcap private key")
+	}
+	if s.AuthenticationMethod == AuthenticationMethodZCAP && s.ZCapSettings.Mode != ZCapModeNative && s.ZCapJsonPath != "" && !strings.HasPrefix(s.ZCapJsonPath, "https") {
 		return errors.New("invalid or empty zcap request url")
 	}
-	if (s.AuthenticationMethod != AuthenticationMethodNone && s.AuthenticationMethod != AuthenticationMethodZCAP) && len(s.AllowedHosts) < 1 {
+	if s.AuthenticationMethod == AuthenticationMethodZCAP && s.ZCapSettings.AdapterTimeoutInSeconds < 0 {
+		return errors.New("invalid zcap adapter timeout")
+	}
+	if s.AuthenticationMethod == AuthenticationMethodZCAP && (s.ZCapSettings.AdapterPoolSize < 0 || s.ZCapSettings.AdapterMaxConcurrency < 0) {
+		return errors.New("invalid zcap adapter pool size or concurrency")
+	}
+	if s.AuthenticationMethod == AuthenticationMethodZCAP && s.ZCapSettings.Mode != ZCapModeNative {
+		if err := ValidateZCapAdapter(s.ZCapSettings, GetZCapAdapterAllowedPaths()); err != nil {
+			return err
+		}
+	}
+	if (s.AuthenticationMethod != AuthenticationMethodNone && s.AuthenticationMethod != AuthenticationMethodZCAP && !isAzureADBlobOnly) && len(s.AllowedHosts) < 1 {
 		return errors.New("configure allowed hosts in the authentication section")
 	}
 	if s.HaveSecureHeaders() && len(s.AllowedHosts) < 1 {
 		return errors.New("configure allowed hosts in the authentication section")
 	}
+	if _, err := GetTLSMinVersion(s.TLSMinVersion); err != nil {
+		return err
+	}
+	if _, err := GetTLSCipherSuites(s.TLSCipherSuites); err != nil {
+		return err
+	}
+	if _, err := GetTLSPinnedPublicKeys(s.TLSPinnedPublicKeys); err != nil {
+		return err
+	}
+	if _, err := GetTLSPinnedHosts(s.TLSPinnedHosts, s.TLSPinnedPublicKeys); err != nil {
+		return err
+	}
+	if s.TLSClientAuth && (s.TLSClientCert == "" || s.TLSClientKey == "") && s.TLSClientPKCS12 == "" {
+		return errors.New("invalid or empty tls client certificate and key")
+	}
+	if s.ProxyType == ProxyTypeUrl {
+		if _, err := GetProxyURL(s.ProxyUrl); err != nil {
+			return err
+		}
+	}
+	if _, err := GetNoProxyRules(s.NoProxy); err != nil {
+		return err
+	}
+	if err := validateDNSSettings(s.DNSOverrides, s.DNSServer); err != nil {
+		return err
+	}
+	if t := s.TransportSettings; t.MaxIdleConns < 0 || t.MaxIdleConnsPerHost < 0 || t.IdleConnTimeoutInSeconds < 0 || t.DialTimeoutInSeconds < 0 || t.TLSHandshakeTimeoutInSeconds < 0 || t.KeepAliveInSeconds < 0 {
+		return errors.New("invalid transport settings. values can't be negative")
+	}
+	for _, rateLimit := range s.RateLimits {
+		if strings.TrimSpace(rateLimit.Host) == "" {
+			return errors.New("invalid or empty rate limit host")
+		}
+		if rateLimit.RequestsPerSecond < 0 || rateLimit.Burst < 0 || rateLimit.MaxConcurrency < 0 {
+			return fmt.Errorf("invalid rate limit for host %s", rateLimit.Host)
+		}
+	}
+	if s.CircuitBreaker.FailureThreshold < 0 || s.CircuitBreaker.OpenDurationInSeconds < 0 {
+		return errors.New("invalid circuit breaker failure threshold or open duration")
+	}
+	if s.StaleMaxAgeInSeconds < 0 {
+		return errors.New("invalid stale max age")
+	}
+	if s.PaginationMaxPages < 0 || s.PaginationMaxPages > PaginationMaxPagesLimit {
+		return fmt.Errorf("invalid pagination max pages. value must be between 0 (default of %d pages) and %d", DefaultPaginationMaxPages, PaginationMaxPagesLimit)
+	}
+	for _, profile := range s.HostProfiles {
+		if u, err := url.Parse(strings.TrimSpace(profile.URLPrefix)); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid or empty url prefix for host profile %s", profile.Name)
+		}
+		if err := profile.Settings.Validate(); err != nil {
+			return fmt.Errorf("invalid host profile %s. %w", profile.Name, err)
+		}
+	}
 	return nil
 }
 
 func (s *InfinitySettings) HaveSecureHeaders() bool {
+	for _, profile := range s.HostProfiles {
+		if profile.Settings.HaveSecureHeaders() {
+			return true
+		}
+	}
 	if len(s.CustomHeaders) > 0 {
 		for k := range s.CustomHeaders {
 			if textproto.CanonicalMIMEHeaderKey(k) == "Accept" {
@@ -158,34 +431,64 @@ type RefData struct {
 }
 
 type InfinitySettingsJson struct {
-	IsMock                   bool           `json:"is_mock,omitempty"`
-	AuthenticationMethod     string         `json:"auth_method,omitempty"`
-	APIKeyKey                string         `json:"apiKeyKey,omitempty"`
-	APIKeyType               string         `json:"apiKeyType,omitempty"`
-	ZCapJsonPath             string         `json:"zCapJsonPath,omitempty"`
-	OAuth2Settings           OAuth2Settings `json:"oauth2,omitempty"`
-	AWSSettings              AWSSettings    `json:"aws,omitempty"`
-	ForwardOauthIdentity     bool           `json:"oauthPassThru,omitempty"`
-	InsecureSkipVerify       bool           `json:"tlsSkipVerify,omitempty"`
-	ServerName               string         `json:"serverName,omitempty"`
-	TLSClientAuth            bool           `json:"tlsAuth,omitempty"`
-	TLSAuthWithCACert        bool           `json:"tlsAuthWithCACert,omitempty"`
-	TimeoutInSeconds         int64          `json:"timeoutInSeconds,omitempty"`
-	ProxyType                ProxyType      `json:"proxy_type,omitempty"`
-	ProxyUrl                 string         `json:"proxy_url,omitempty"`
-	AllowedHosts             []string       `json:"allowedHosts,omitempty"`
-	EnableOpenAPI            bool           `json:"enableOpenApi,omitempty"`
-	OpenAPIVersion           string         `json:"openApiVersion,omitempty"`
-	OpenAPIUrl               string         `json:"openApiUrl,omitempty"`
-	OpenAPIBaseUrl           string         `json:"openAPIBaseURL,omitempty"`
-	ReferenceData            []RefData      `json:"refData,omitempty"`
-	CustomHealthCheckEnabled bool           `json:"customHealthCheckEnabled,omitempty"`
-	CustomHealthCheckUrl     string         `json:"customHealthCheckUrl,omitempty"`
-	AzureBlobAccountUrl      string         `json:"azureBlobAccountUrl,omitempty"`
-	AzureBlobAccountName     string         `json:"azureBlobAccountName,omitempty"`
+	IsMock                   bool                   `json:"is_mock,omitempty"`
+	AuthenticationMethod     string                 `json:"auth_method,omitempty"`
+	APIKeyKey                string                 `json:"apiKeyKey,omitempty"`
+	APIKeyType               string                 `json:"apiKeyType,omitempty"`
+	ZCapJsonPath             string                 `json:"zCapJsonPath,omitempty"`
+	ZCapSettings             ZCapSettings           `json:"zcap,omitempty"`
+	OAuth2Settings           OAuth2Settings         `json:"oauth2,omitempty"`
+	AWSSettings              AWSSettings            `json:"aws,omitempty"`
+	ForwardOauthIdentity     bool                   `json:"oauthPassThru,omitempty"`
+	InsecureSkipVerify       bool                   `json:"tlsSkipVerify,omitempty"`
+	ServerName               string                 `json:"serverName,omitempty"`
+	TLSClientAuth            bool                   `json:"tlsAuth,omitempty"`
+	TLSAuthWithCACert        bool                   `json:"tlsAuthWithCACert,omitempty"`
+	TLSMinVersion            string                 `json:"tlsMinVersion,omitempty"`
+	TLSCipherSuites          []string               `json:"tlsCipherSuites,omitempty"`
+	TLSPinnedPublicKeys      []string               `json:"tlsPinnedPublicKeys,omitempty"`
+	TLSPinnedHosts           []string               `json:"tlsPinnedHosts,omitempty"`
+	TimeoutInSeconds         int64                  `json:"timeoutInSeconds,omitempty"`
+	ProxyType                ProxyType              `json:"proxy_type,omitempty"`
+	ProxyUrl                 string                 `json:"proxy_url,omitempty"`
+	ProxyUsername            string                 `json:"proxy_username,omitempty"`
+	NoProxy                  []string               `json:"no_proxy,omitempty"`
+	DNSOverrides             []DNSOverride          `json:"dnsOverrides,omitempty"`
+	DNSServer                string                 `json:"dnsServer,omitempty"`
+	TransportSettings        TransportSettings      `json:"transport,omitempty"`
+	RateLimits               []RateLimit            `json:"rateLimits,omitempty"`
+	CircuitBreaker           CircuitBreakerSettings `json:"circuitBreaker,omitempty"`
+	ServeStaleOnError        bool                   `json:"serveStaleOnError,omitempty"`
+	StaleMaxAgeInSeconds     int64                  `json:"staleMaxAgeInSeconds,omitempty"`
+	PaginationMaxPages       int                    `json:"paginationMaxPages,omitempty"`
+	AllowedHosts             []string               `json:"allowedHosts,omitempty"`
+	EnableOpenAPI            bool                   `json:"enableOpenApi,omitempty"`
+	OpenAPIVersion           string                 `json:"openApiVersion,omitempty"`
+	OpenAPIUrl               string                 `json:"openApiUrl,omitempty"`
+	OpenAPIBaseUrl           string                 `json:"openAPIBaseURL,omitempty"`
+	ReferenceData            []RefData              `json:"refData,omitempty"`
+	CustomHealthCheckEnabled bool                   `json:"customHealthCheckEnabled,omitempty"`
+	CustomHealthCheckUrl     string                 `json:"customHealthCheckUrl,omitempty"`
+	AzureBlobAccountUrl      string                 `json:"azureBlobAccountUrl,omitempty"`
+	AzureBlobAccountName     string                 `json:"azureBlobAccountName,omitempty"`
+	AzureADSettings          AzureADSettings        `json:"azureAD,omitempty"`
+	HMACSettings             HMACSettings           `json:"hmac,omitempty"`
 }
 
 func LoadSettings(config backend.DataSourceInstanceSettings) (settings InfinitySettings, err error) {
+	config.DecryptedSecureJSONData, err = ResolveSecretReferences(config.DecryptedSecureJSONData, GetSecretReferenceConfig())
+	if err != nil {
+		return settings, err
+	}
+	settings, err = loadSettings(config)
+	if err != nil {
+		return settings, err
+	}
+	settings.HostProfiles, err = loadHostProfiles(config)
+	return settings, err
+}
+
+func loadSettings(config backend.DataSourceInstanceSettings) (settings InfinitySettings, err error) {
 	settings.URL = config.URL
 	if config.URL == "__IGNORE_URL__" {
 		settings.URL = ""
@@ -206,7 +509,30 @@ func LoadSettings(config backend.DataSourceInstanceSettings) (settings InfinityS
 		settings.ApiKeyKey = infJson.APIKeyKey
 		settings.ApiKeyType = infJson.APIKeyType
 		settings.ZCapJsonPath = infJson.ZCapJsonPath
+		settings.ZCapSettings = infJson.ZCapSettings
 		settings.AWSSettings = infJson.AWSSettings
+		settings.AzureADSettings = infJson.AzureADSettings
+		if settings.AuthenticationMethod == AuthenticationMethodAzureAD && settings.AzureADSettings.AuthType == "" {
+			settings.AzureADSettings.AuthType = AzureADAuthTypeClientSecret
+		}
+		settings.HMACSettings = infJson.HMACSettings
+		if settings.AuthenticationMethod == AuthenticationMethodHMAC {
+			if settings.HMACSettings.StringToSign == "" {
+				settings.HMACSettings.StringToSign = HMACDefaultStringToSign
+			}
+			if settings.HMACSettings.Algorithm == "" {
+				settings.HMACSettings.Algorithm = HMACAlgorithmSHA256
+			}
+			if settings.HMACSettings.Encoding == "" {
+				settings.HMACSettings.Encoding = HMACEncodingHex
+			}
+			if settings.HMACSettings.TimestampFormat == "" {
+				settings.HMACSettings.TimestampFormat = HMACTimestampFormatUnix
+			}
+			if settings.HMACSettings.SignatureHeader == "" {
+				settings.HMACSettings.SignatureHeader = "X-Signature"
+			}
+		}
 		if settings.ApiKeyType == "" {
 			settings.ApiKeyType = "header"
 		}
@@ -218,9 +544,23 @@ func LoadSettings(config backend.DataSourceInstanceSettings) (settings InfinityS
 		settings.ServerName = infJson.ServerName
 		settings.TLSClientAuth = infJson.TLSClientAuth
 		settings.TLSAuthWithCACert = infJson.TLSAuthWithCACert
+		settings.TLSMinVersion = infJson.TLSMinVersion
+		settings.TLSCipherSuites = infJson.TLSCipherSuites
+		settings.TLSPinnedPublicKeys = infJson.TLSPinnedPublicKeys
+		settings.TLSPinnedHosts = infJson.TLSPinnedHosts
 		settings.TimeoutInSeconds = 60
 		settings.ProxyType = infJson.ProxyType
 		settings.ProxyUrl = infJson.ProxyUrl
+		settings.ProxyUsername = infJson.ProxyUsername
+		settings.NoProxy = infJson.NoProxy
+		settings.DNSOverrides = infJson.DNSOverrides
+		settings.DNSServer = infJson.DNSServer
+		settings.TransportSettings = infJson.TransportSettings
+		settings.RateLimits = infJson.RateLimits
+		settings.CircuitBreaker = infJson.CircuitBreaker
+		settings.ServeStaleOnError = infJson.ServeStaleOnError
+		settings.StaleMaxAgeInSeconds = infJson.StaleMaxAgeInSeconds
+		settings.PaginationMaxPages = infJson.PaginationMaxPages
 		if settings.ProxyType == "" {
 			settings.ProxyType = ProxyTypeEnv
 		}
@@ -258,6 +598,18 @@ func LoadSettings(config backend.DataSourceInstanceSettings) (settings InfinityS
 	if val, ok := config.DecryptedSecureJSONData["tlsClientKey"]; ok {
 		settings.TLSClientKey = val
 	}
+	if val, ok := config.DecryptedSecureJSONData["tlsClientPKCS12"]; ok {
+		settings.TLSClientPKCS12 = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["tlsClientPKCS12Password"]; ok {
+		settings.TLSClientPKCS12Password = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["proxyPassword"]; ok {
+		settings.ProxyPassword = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["proxyCACert"]; ok {
+		settings.ProxyCACert = val
+	}
 	if val, ok := config.DecryptedSecureJSONData["bearerToken"]; ok {
 		settings.BearerToken = val
 	}
@@ -270,6 +622,21 @@ func LoadSettings(config backend.DataSourceInstanceSettings) (settings InfinityS
 	if val, ok := config.DecryptedSecureJSONData["azureBlobAccountKey"]; ok {
 		settings.AzureBlobAccountKey = val
 	}
+	if val, ok := config.DecryptedSecureJSONData["azureADClientSecret"]; ok {
+		settings.AzureADSettings.ClientSecret = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["azureADClientCertificate"]; ok {
+		settings.AzureADSettings.ClientCertificate = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["azureADClientCertificatePassword"]; ok {
+		settings.AzureADSettings.ClientCertificatePassword = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["hmacSecret"]; ok {
+		settings.HMACSettings.Secret = val
+	}
+	if val, ok := config.DecryptedSecureJSONData["zcapPrivateKey"]; ok {
+		settings.ZCapSettings.PrivateKey = val
+	}
 	settings.CustomHeaders = GetSecrets(config, "httpHeaderName", "httpHeaderValue")
 	settings.SecureQueryFields = GetSecrets(config, "secureQueryName", "secureQueryValue")
 	settings.OAuth2Settings.EndpointParams = GetSecrets(config, "oauth2EndPointParamsName", "oauth2EndPointParamsValue")
@@ -282,7 +649,13 @@ func LoadSettings(config backend.DataSourceInstanceSettings) (settings InfinityS
 			settings.AuthenticationMethod = AuthenticationMethodForwardOauth
 		}
 	}
-	if settings.AuthenticationMethod == AuthenticationMethodAzureBlob && settings.AzureBlobAccountUrl == "" {
+	if settings.AuthenticationMethod == AuthenticationMethodZCAP && settings.ZCapSettings.Mode == "" {
+		settings.ZCapSettings.Mode = ZCapModeMercury
+		if settings.ZCapSettings.PrivateKey != "" {
+			settings.ZCapSettings.Mode = ZCapModeNative
+		}
+	}
+	if (settings.AuthenticationMethod == AuthenticationMethodAzureBlob || (settings.AuthenticationMethod == AuthenticationMethodAzureAD && settings.AzureBlobAccountName != "")) && settings.AzureBlobAccountUrl == "" {
 		settings.AzureBlobAccountUrl = "https://%s.blob.core.windows.net/"
 	}
 	return
@@ -368,6 +368,135 @@ func TestInfinitySettings_Validate(t *testing.T) {
 			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodBearerToken, BearerToken: "foo"},
 			wantErr:  errors.New("configure allowed hosts in the authentication section"),
 		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD},
+			wantErr:  errors.New("invalid or empty azure ad tenant id or client id"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{TenantID: "foo", ClientID: "bar"}},
+			wantErr:  errors.New("invalid or empty azure ad client secret"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{AuthType: models.AzureADAuthTypeClientCertificate, TenantID: "foo", ClientID: "bar", ClientSecret: "baz"}},
+			wantErr:  errors.New("invalid or empty azure ad client certificate"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{TenantID: "foo", ClientID: "bar", ClientSecret: "baz"}},
+			wantErr:  errors.New("invalid or empty azure ad scopes"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureADSettings: models.AzureADSettings{TenantID: "foo", ClientID: "bar", ClientSecret: "baz", Scopes: []string{"api://foo/.default"}}},
+			wantErr:  errors.New("configure allowed hosts in the authentication section"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureBlobAccountName: "foo", AzureADSettings: models.AzureADSettings{TenantID: "foo", ClientID: "bar", ClientSecret: "baz"}},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureBlobAccountName: "foo", AzureADSettings: models.AzureADSettings{TenantID: "foo", ClientID: "bar", ClientSecret: "baz"}, PaginationMaxPages: 5000},
+			wantErr:  errors.New("invalid pagination max pages. value must be between 0 (default of 5 pages) and 1000"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodZCAP, ZCapSettings: models.ZCapSettings{Mode: models.ZCapModeNative}},
+			wantErr:  errors.New("invalid or empty zcap private key"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodZCAP, ZCapSettings: models.ZCapSettings{Mode: models.ZCapModeNative, PrivateKey: "foo"}},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodHMAC},
+			wantErr:  errors.New("invalid or empty hmac secret"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodHMAC, HMACSettings: models.HMACSettings{Secret: "foo", Algorithm: "md5"}},
+			wantErr:  errors.New("invalid hmac algorithm md5"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodHMAC, HMACSettings: models.HMACSettings{Secret: "foo", Algorithm: models.HMACAlgorithmSHA256, SignatureHeader: "X-Signature"}},
+			wantErr:  errors.New("configure allowed hosts in the authentication section"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodAzureAD, AzureBlobAccountName: "foo", AzureADSettings: models.AzureADSettings{TenantID: "foo", ClientID: "bar", ClientSecret: "baz"}},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, TLSMinVersion: "1.4"},
+			wantErr:  errors.New("invalid tls min version 1.4"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, TLSCipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+			wantErr:  errors.New("invalid tls cipher suite TLS_RSA_WITH_RC4_128_SHA"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, TLSPinnedPublicKeys: []string{"sha256/foo"}},
+			wantErr:  errors.New("invalid tls pinned public key sha256/foo"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, TLSClientAuth: true},
+			wantErr:  errors.New("invalid or empty tls client certificate and key"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, TLSClientAuth: true, TLSClientPKCS12: "foo", TLSMinVersion: "TLS1.2", TLSPinnedPublicKeys: []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}, TLSPinnedHosts: []string{"foo.com"}},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, TLSPinnedPublicKeys: []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}},
+			wantErr:  errors.New("tls pinned public keys require the hosts they apply to"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, TLSPinnedPublicKeys: []string{"sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="}, TLSPinnedHosts: []string{"https://foo.com"}},
+			wantErr:  errors.New("invalid tls pinned host https://foo.com. pinned hosts must be host names without scheme or port"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, ProxyType: models.ProxyTypeUrl, ProxyUrl: "ftp://foo.com"},
+			wantErr:  errors.New("invalid proxy url scheme ftp. supported schemes are http, https, socks5 and socks5h"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, ProxyType: models.ProxyTypeEnv, NoProxy: []string{"10.0.0.0/99"}},
+			wantErr:  errors.New("invalid no proxy entry 10.0.0.0/99"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, ProxyType: models.ProxyTypeUrl, ProxyUrl: "socks5://foo.com:1080", NoProxy: []string{"10.0.0.0/8", "*.foo.com"}},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, DNSOverrides: []models.DNSOverride{{Host: "foo.com", IP: "bar"}}},
+			wantErr:  errors.New("invalid dns override ip bar for host foo.com"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, DNSServer: "dns.foo.com"},
+			wantErr:  errors.New("invalid dns server dns.foo.com"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, DNSOverrides: []models.DNSOverride{{Host: "foo.com", Port: "443", IP: "10.0.0.1"}}, DNSServer: "10.0.0.53:5353"},
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, TransportSettings: models.TransportSettings{MaxIdleConnsPerHost: -1}},
+			wantErr:  errors.New("invalid transport settings. values can't be negative"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, RateLimits: []models.RateLimit{{RequestsPerSecond: 1}}},
+			wantErr:  errors.New("invalid or empty rate limit host"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, RateLimits: []models.RateLimit{{Host: "https://foo.com", MaxConcurrency: -1}}},
+			wantErr:  errors.New("invalid rate limit for host https://foo.com"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, CircuitBreaker: models.CircuitBreakerSettings{Enabled: true, FailureThreshold: -1}},
+			wantErr:  errors.New("invalid circuit breaker failure threshold or open duration"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, ServeStaleOnError: true, StaleMaxAgeInSeconds: -1},
+			wantErr:  errors.New("invalid stale max age"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, PaginationMaxPages: 1001},
+			wantErr:  errors.New("invalid pagination max pages. value must be between 0 (default of 5 pages) and 1000"),
+		},
+		{
+			settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodNone, HostProfiles: []models.HostProfile{{Name: "foo"}}},
+			wantErr:  errors.New("invalid or empty url prefix for host profile foo"),
+		},
+		{
+			settings: models.InfinitySettings{AllowedHosts: []string{"https://foo.com"}, HostProfiles: []models.HostProfile{{Name: "foo", URLPrefix: "https://foo.com", Settings: models.InfinitySettings{AuthenticationMethod: models.AuthenticationMethodBearerToken, AllowedHosts: []string{"https://foo.com"}, BearerToken: "foo"}}}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
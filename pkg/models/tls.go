@@ -0,0 +1,95 @@
+package models
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+)
+
+const pinnedPublicKeyPrefix = "sha256/"
+
+// GetTLSMinVersion returns the tls version for the min version setting. Supported values are 1.0, 1.1, 1.2 and 1.3
+func GetTLSMinVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(version)), "TLS") {
+	case "":
+		return 0, nil
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid tls min version %s", version)
+	}
+}
+
+// GetTLSCipherSuites returns the ids of the cipher suites by their names, for example TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256.
+// Only the cipher suites without known security issues are supported. TLS 1.3 cipher suites are not configurable
+func GetTLSCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	supported := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		supported[suite.Name] = suite.ID
+	}
+	ids := []uint16{}
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		id, ok := supported[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid tls cipher suite %s", name)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return ids, nil
+}
+
+// GetTLSPinnedPublicKeys returns the sha256 hashes of the pinned public keys (SPKI). The keys are base64 encoded with optional `sha256/` prefix
+func GetTLSPinnedPublicKeys(keys []string) ([][]byte, error) {
+	hashes := [][]byte{}
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			continue
+		}
+		hash, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(key), pinnedPublicKeyPrefix))
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid tls pinned public key %s", key)
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// GetTLSPinnedHosts returns the lower cased host names the pinned public keys apply to. The hosts are matched against the server name of the tls
+// connection, so ip addresses can't be pinned. Pinned keys without hosts are rejected so that the pins never apply to the token endpoints
+func GetTLSPinnedHosts(hosts []string, pinnedPublicKeys []string) ([]string, error) {
+	out := []string{}
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		if strings.ContainsAny(host, ":/") || net.ParseIP(host) != nil {
+			return nil, fmt.Errorf("invalid tls pinned host %s. pinned hosts must be host names without scheme or port", host)
+		}
+		out = append(out, host)
+	}
+	if len(out) < 1 && slices.ContainsFunc(pinnedPublicKeys, func(key string) bool { return strings.TrimSpace(key) != "" }) {
+		return nil, errors.New("tls pinned public keys require the hosts they apply to")
+	}
+	return out, nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvZCapAdapterAllowedPaths is the plugin config environment variable of the allowed mercury client adapter binaries. Comma separated
+// list of the binaries or the directories of the binaries. Grafana passes `zcap_adapter_allowed_paths` of the plugin config section as it
+const EnvZCapAdapterAllowedPaths = "GF_PLUGIN_ZCAP_ADAPTER_ALLOWED_PATHS"
+
+// GetZCapAdapterAllowedPaths returns the allowed mercury client adapter paths from the plugin config environment variables
+func GetZCapAdapterAllowedPaths() []string {
+	return splitConfigList(os.Getenv(EnvZCapAdapterAllowedPaths))
+}
+
+/*
+ * ValidateZCapAdapter verifies the mercury client adapter binary of the datasource is allowed by the plugin config.
+ *
+ * The datasource settings can be edited by any datasource editor while the adapter is executed by the grafana server. So the
+ * custom adapter path and arguments are rejected unless the adapter is one of the allowed paths or inside one of the allowed
+ * directories of the plugin config. The default `mercury-client` adapter without arguments is always allowed.
+ */
+func ValidateZCapAdapter(settings ZCapSettings, allowedPaths []string) error {
+	adapterPath := strings.TrimSpace(settings.AdapterPath)
+	if adapterPath == "" && len(settings.AdapterArgs) == 0 {
+		return nil
+	}
+	if len(allowedPaths) == 0 {
+		return errors.New("custom zcap adapter path and arguments are not allowed. configure zcap_adapter_allowed_paths in the plugin config")
+	}
+	if adapterPath == "" {
+		return errors.New("zcap adapter arguments require an allowed adapter path")
+	}
+	path := filepath.Clean(adapterPath)
+	if !filepath.IsAbs(path) {
+		return errors.New("zcap adapter path must be absolute")
+	}
+	// symlinks are resolved before the allow-list check so that they can't point outside of the allowed paths
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("invalid zcap adapter path %s. %w", adapterPath, err)
+	}
+	for _, allowedPath := range allowedPaths {
+		if allowedPath, err := filepath.EvalSymlinks(filepath.Clean(allowedPath)); err == nil && filepath.IsAbs(allowedPath) && allowedPath == resolvedPath {
+			return nil
+		}
+	}
+	if isPathAllowed(resolvedPath, allowedPaths) {
+		return nil
+	}
+	return fmt.Errorf("zcap adapter %s is not in the allowed adapter paths", adapterPath)
+}
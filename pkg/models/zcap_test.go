@@ -0,0 +1,83 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestValidateZCapAdapter(t *testing.T) {
+	adaptersDir := t.TempDir()
+	otherDir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(adaptersDir, "mercury-client"), []byte("#!/bin/sh"), 0o755))
+	require.Nil(t, os.WriteFile(filepath.Join(otherDir, "mercury-client"), []byte("#!/bin/sh"), 0o755))
+	require.Nil(t, os.Symlink(filepath.Join(otherDir, "mercury-client"), filepath.Join(adaptersDir, "link")))
+	tests := []struct {
+		name         string
+		settings     models.ZCapSettings
+		allowedPaths []string
+		wantErr      string
+	}{
+		{
+			name: "should allow the default adapter without the allowed paths",
+		},
+		{
+			name:     "should not allow the custom adapter path without the allowed paths",
+			settings: models.ZCapSettings{AdapterPath: filepath.Join(adaptersDir, "mercury-client")},
+			wantErr:  "custom zcap adapter path and arguments are not allowed. configure zcap_adapter_allowed_paths in the plugin config",
+		},
+		{
+			name:     "should not allow the adapter arguments without the allowed paths",
+			settings: models.ZCapSettings{AdapterArgs: []string{"--config", "/etc/passwd"}},
+			wantErr:  "custom zcap adapter path and arguments are not allowed. configure zcap_adapter_allowed_paths in the plugin config",
+		},
+		{
+			name:         "should allow the adapter inside the allowed directory",
+			settings:     models.ZCapSettings{AdapterPath: filepath.Join(adaptersDir, "mercury-client"), AdapterArgs: []string{"--json"}},
+			allowedPaths: []string{adaptersDir},
+		},
+		{
+			name:         "should allow the adapter listed in the allowed paths",
+			settings:     models.ZCapSettings{AdapterPath: filepath.Join(otherDir, "mercury-client")},
+			allowedPaths: []string{adaptersDir, filepath.Join(otherDir, "mercury-client")},
+		},
+		{
+			name:         "should not allow the adapter outside of the allowed paths",
+			settings:     models.ZCapSettings{AdapterPath: filepath.Join(otherDir, "mercury-client")},
+			allowedPaths: []string{adaptersDir},
+			wantErr:      "zcap adapter " + filepath.Join(otherDir, "mercury-client") + " is not in the allowed adapter paths",
+		},
+		{
+			name:         "should not allow the symlinks pointing outside of the allowed paths",
+			settings:     models.ZCapSettings{AdapterPath: filepath.Join(adaptersDir, "link")},
+			allowedPaths: []string{adaptersDir},
+			wantErr:      "zcap adapter " + filepath.Join(adaptersDir, "link") + " is not in the allowed adapter paths",
+		},
+		{
+			name:         "should not allow the relative adapter paths",
+			settings:     models.ZCapSettings{AdapterPath: "mercury-client"},
+			allowedPaths: []string{adaptersDir},
+			wantErr:      "zcap adapter path must be absolute",
+		},
+		{
+			name:         "should not allow the adapter arguments of the default adapter",
+			settings:     models.ZCapSettings{AdapterArgs: []string{"--json"}},
+			allowedPaths: []string{adaptersDir},
+			wantErr:      "zcap adapter arguments require an allowed adapter path",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.ValidateZCapAdapter(tt.settings, tt.allowedPaths)
+			if tt.wantErr != "" {
+				require.NotNil(t, err)
+				require.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
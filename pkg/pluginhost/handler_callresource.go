@@ -30,6 +30,7 @@ func (host *PluginHost) withDatasourceHandlerFunc(getHandler func(d *instanceSet
 			http.Error(rw, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		defer client.release()
 		h := getHandler(client)
 		h.ServeHTTP(rw, r)
 	}
@@ -43,6 +44,7 @@ func (host *PluginHost) getGraphQLHandler() http.Handler {
 			w.Write([]byte(err.Error())) //nolint
 			return
 		}
+		defer client.release()
 		schemaConfig := graphql.SchemaConfig{
 			Query: graphql.NewObject(
 				graphql.ObjectConfig{
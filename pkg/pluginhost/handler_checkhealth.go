@@ -4,11 +4,17 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
 	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
 )
 
+// certificates expiring within this duration are reported as warnings in the health check
+const tlsCertificateExpiryWarning = 30 * 24 * time.Hour
+
 // CheckHealth handles health checks sent from Grafana to the plugin.
 func (ds *PluginHost) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	logger := backend.Logger.FromContext(ctx)
@@ -26,7 +32,14 @@ func (ds *PluginHost) CheckHealth(ctx context.Context, req *backend.CheckHealthR
 func CheckHealth(ctx context.Context, ds *PluginHost, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	logger := backend.Logger.FromContext(ctx)
 	client, err := getInstance(ctx, ds.im, req.PluginContext)
-	if err != nil || client == nil || client.client == nil {
+	if err != nil || client == nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "failed to get plugin instance",
+		}, nil
+	}
+	defer client.release()
+	if client.client == nil {
 		return &backend.CheckHealthResult{
 			Status:  backend.HealthStatusError,
 			Message: "failed to get plugin instance",
@@ -48,6 +61,13 @@ func CheckHealth(ctx context.Context, ds *PluginHost, req *backend.CheckHealthRe
 			Message: fmt.Sprintf("invalid settings. %s", err.Error()),
 		}, nil
 	}
+	tlsReport, err := getTLSHealthReport(client.client.Settings, time.Now())
+	if err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("invalid tls settings. %s", err.Error()),
+		}, nil
+	}
 	if client.client.Settings.CustomHealthCheckEnabled && client.client.Settings.CustomHealthCheckUrl != "" {
 		_, statusCode, _, err := client.client.GetResults(ctx, models.Query{
 			Type:   models.QueryTypeUQL,
@@ -72,12 +92,48 @@ func CheckHealth(ctx context.Context, ds *PluginHost, req *backend.CheckHealthRe
 		if statusCode == http.StatusOK {
 			return &backend.CheckHealthResult{
 				Status:  backend.HealthStatusOk,
-				Message: fmt.Sprintf("health check successful with url %s. http status code received: %d", client.client.Settings.CustomHealthCheckUrl, statusCode),
+				Message: withTLSReport(fmt.Sprintf("health check successful with url %s. http status code received: %d", client.client.Settings.CustomHealthCheckUrl, statusCode), tlsReport),
 			}, nil
 		}
 	}
 	return &backend.CheckHealthResult{
 		Status:  backend.HealthStatusOk,
-		Message: "OK",
+		Message: withTLSReport("OK", tlsReport),
 	}, nil
 }
+
+// getTLSHealthReport summarizes the tls hardening options and the expiry of the configured certificates. Expired certificates are reported as errors
+func getTLSHealthReport(settings models.InfinitySettings, now time.Time) (string, error) {
+	report := []string{}
+	if settings.TLSMinVersion != "" {
+		report = append(report, fmt.Sprintf("TLS min version %s", settings.TLSMinVersion))
+	}
+	if len(settings.TLSCipherSuites) > 0 {
+		report = append(report, fmt.Sprintf("%d cipher suites allowed", len(settings.TLSCipherSuites)))
+	}
+	if len(settings.TLSPinnedPublicKeys) > 0 {
+		report = append(report, fmt.Sprintf("%d public keys pinned for %s", len(settings.TLSPinnedPublicKeys), strings.Join(settings.TLSPinnedHosts, ", ")))
+	}
+	certificates, err := infinity.GetTLSCertificatesInfo(settings)
+	if err != nil {
+		return "", err
+	}
+	for _, cert := range certificates {
+		if now.After(cert.NotAfter) {
+			return "", fmt.Errorf("tls %s %s expired on %s", cert.Name, cert.Subject, cert.NotAfter.Format(time.RFC3339))
+		}
+		expiry := fmt.Sprintf("%s %s expires on %s", cert.Name, cert.Subject, cert.NotAfter.Format(time.RFC3339))
+		if cert.NotAfter.Sub(now) < tlsCertificateExpiryWarning {
+			expiry = fmt.Sprintf("warning: %s %s expires in %d days", cert.Name, cert.Subject, int(cert.NotAfter.Sub(now).Hours()/24))
+		}
+		report = append(report, expiry)
+	}
+	return strings.Join(report, ". "), nil
+}
+
+func withTLSReport(message string, tlsReport string) string {
+	if tlsReport == "" {
+		return message
+	}
+	return message + ". " + tlsReport
+}
@@ -26,6 +26,8 @@ func (ds *PluginHost) QueryData(ctx context.Context, req *backend.QueryDataReque
 		logger.Error("error getting infinity instance", "error", err.Error())
 		return response, fmt.Errorf("error getting infinity instance. %w", err)
 	}
+	defer client.release()
+	ctx = models.WithPaginationMaxPages(ctx, client.client.Settings.PaginationMaxPages)
 	for _, q := range req.Queries {
 		res := backend.DataResponse{}
 		query, err := models.LoadQuery(ctx, q, req.PluginContext)
@@ -57,7 +59,7 @@ func QueryData(ctx context.Context, backendQuery backend.DataQuery, infClient in
 	logger := backend.Logger.FromContext(ctx)
 	ctx, span := tracing.DefaultTracer().Start(ctx, "QueryData")
 	defer span.End()
-	query, err := models.LoadQuery(ctx, backendQuery, pluginContext)
+	query, err := models.LoadQuery(models.WithPaginationMaxPages(ctx, infClient.Settings.PaginationMaxPages), backendQuery, pluginContext)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(500, err.Error())
@@ -118,7 +120,8 @@ func QueryDataQuery(ctx context.Context, query models.Query, infClient infinity.
 		query, _ := infinity.UpdateQueryWithReferenceData(ctx, query, infClient.Settings)
 		switch query.Source {
 		case "url", "azure-blob":
-			if (infClient.Settings.AuthenticationMethod != models.AuthenticationMethodAzureBlob && infClient.Settings.AuthenticationMethod != models.AuthenticationMethodNone && infClient.Settings.AuthenticationMethod != models.AuthenticationMethodZCAP) && len(infClient.Settings.AllowedHosts) < 1 {
+			isAzureADBlobQuery := query.Source == "azure-blob" && infClient.Settings.AuthenticationMethod == models.AuthenticationMethodAzureAD
+			if (infClient.Settings.AuthenticationMethod != models.AuthenticationMethodAzureBlob && infClient.Settings.AuthenticationMethod != models.AuthenticationMethodNone && infClient.Settings.AuthenticationMethod != models.AuthenticationMethodZCAP && !isAzureADBlobQuery) && len(infClient.Settings.AllowedHosts) < 1 {
 				response.Error = errors.New("datasource is missing allowed hosts/URLs. Configure it in the datasource settings page for enhanced security")
 				return response
 			}
@@ -138,7 +141,7 @@ func QueryDataQuery(ctx context.Context, query models.Query, infClient infinity.
 				response.Error = fmt.Errorf("error getting data frame. %w", err)
 				return response
 			}
-			if frame != nil && infClient.Settings.AuthenticationMethod != models.AuthenticationMethodAzureBlob && infClient.Settings.AuthenticationMethod != models.AuthenticationMethodNone && infClient.Settings.AuthenticationMethod != "" && len(infClient.Settings.AllowedHosts) < 1 {
+			if frame != nil && infClient.Settings.AuthenticationMethod != models.AuthenticationMethodAzureBlob && infClient.Settings.AuthenticationMethod != models.AuthenticationMethodNone && infClient.Settings.AuthenticationMethod != "" && !isAzureADBlobQuery && len(infClient.Settings.AllowedHosts) < 1 {
 				frame.AppendNotices(data.Notice{
 					Text: "Datasource is missing allowed hosts/URLs. Configure it in the datasource settings page for enhanced security.",
 				})
@@ -147,6 +150,21 @@ func QueryDataQuery(ctx context.Context, query models.Query, infClient infinity.
 				frame, _ = infinity.WrapMetaForRemoteQuery(ctx, frame, nil, query)
 				response.Frames = append(response.Frames, frame)
 			}
+		case "edv":
+			frame, err := infinity.GetFrameForEDVSources(ctx, query, infClient)
+			if err != nil {
+				logger.Error("error while performing the infinity edv query", "msg", err.Error())
+				span.RecordError(err)
+				span.SetStatus(500, err.Error())
+				frame, _ = infinity.WrapMetaForRemoteQuery(ctx, frame, err, query)
+				response.Frames = append(response.Frames, frame)
+				response.Error = fmt.Errorf("error getting data frame from edv. %w", err)
+				return response
+			}
+			if frame != nil {
+				frame, _ = infinity.WrapMetaForRemoteQuery(ctx, frame, nil, query)
+				response.Frames = append(response.Frames, frame)
+			}
 		case "inline":
 			frame, err := infinity.GetFrameForInlineSources(ctx, query)
 			if err != nil {
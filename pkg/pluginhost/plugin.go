@@ -2,7 +2,10 @@ package pluginhost
 
 import (
 	"context"
+	"maps"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
@@ -28,31 +31,134 @@ func NewDatasource() datasource.ServeOpts {
 }
 
 type instanceSettings struct {
+	mu     sync.RWMutex
 	client *infinity.Client
+	// users are the in-flight requests using the client. The client is disposed only after they are complete
+	users *sync.WaitGroup
+	done  chan struct{}
+	// exited is closed once the secret references refresh is stopped
+	exited chan struct{}
 }
 
-func (is *instanceSettings) Dispose() {}
+// newClient creates the clients of the instances. Replaced in the tests to control the client creation
+var newClient = infinity.NewClient
+
+// Dispose stops the secret references refresh and waits for it to exit before disposing the client,
+// so that a client created by an in-flight refresh is never swapped in after the instance is disposed
+func (is *instanceSettings) Dispose() {
+	if is.done != nil {
+		close(is.done)
+		<-is.exited
+	}
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	if is.client != nil {
+		disposeWhenUnused(is.client, is.users)
+	}
+}
+
+// disposeWhenUnused disposes the client in the background once the requests using it are complete
+func disposeWhenUnused(client *infinity.Client, users *sync.WaitGroup) {
+	go func() {
+		users.Wait()
+		client.Dispose()
+	}()
+}
 
 func newDataSourceInstance(ctx context.Context, setting backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 	settings, err := models.LoadSettings(setting)
 	if err != nil {
 		return nil, err
 	}
-	client, err := infinity.NewClient(ctx, settings)
+	client, err := newClient(ctx, settings)
 	if err != nil {
 		return nil, err
 	}
-	return &instanceSettings{
+	is := &instanceSettings{
 		client: client,
-	}, nil
+		users:  &sync.WaitGroup{},
+	}
+	refConfig := models.GetSecretReferenceConfig()
+	if refConfig.Enabled() && refConfig.RefreshInterval > 0 && models.HasSecretReferences(setting.DecryptedSecureJSONData) {
+		is.done, is.exited = make(chan struct{}), make(chan struct{})
+		go is.refreshSecretReferences(setting, refConfig)
+	}
+	return is, nil
+}
+
+// refreshSecretReferences re-reads the secret references periodically and replaces the client when any of the secrets changed,
+// so that the rotated secrets are applied without recreating the instance
+func (is *instanceSettings) refreshSecretReferences(setting backend.DataSourceInstanceSettings, refConfig models.SecretReferenceConfig) {
+	defer close(is.exited)
+	secrets, _ := models.ResolveSecretReferences(setting.DecryptedSecureJSONData, refConfig)
+	ticker := time.NewTicker(refConfig.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-is.done:
+			return
+		case <-ticker.C:
+			newSecrets, err := models.ResolveSecretReferences(setting.DecryptedSecureJSONData, refConfig)
+			if err != nil {
+				backend.Logger.Error("error refreshing secret references. using the previous secrets", "datasource", setting.UID, "error", err.Error())
+				continue
+			}
+			if maps.Equal(secrets, newSecrets) {
+				continue
+			}
+			settings, err := models.LoadSettings(setting)
+			if err != nil {
+				backend.Logger.Error("error loading settings with the refreshed secret references", "datasource", setting.UID, "error", err.Error())
+				continue
+			}
+			client, err := newClient(context.Background(), settings)
+			if err != nil {
+				backend.Logger.Error("error creating client with the refreshed secret references", "datasource", setting.UID, "error", err.Error())
+				continue
+			}
+			select {
+			case <-is.done:
+				// the instance is disposed while the client was created
+				client.Dispose()
+				return
+			default:
+			}
+			secrets = newSecrets
+			is.mu.Lock()
+			oldClient, oldUsers := is.client, is.users
+			client.ShareStateOf(oldClient)
+			is.client, is.users = client, &sync.WaitGroup{}
+			is.mu.Unlock()
+			if oldClient != nil {
+				disposeWhenUnused(oldClient, oldUsers)
+			}
+			backend.Logger.Info("secret references refreshed", "datasource", setting.UID)
+		}
+	}
+}
+
+// acquire returns a snapshot of the instance with its current client and marks the client as in use until the snapshot is released.
+// The client is replaced when the secret references are refreshed
+func (is *instanceSettings) acquire() *instanceSettings {
+	is.mu.RLock()
+	defer is.mu.RUnlock()
+	is.users.Add(1)
+	return &instanceSettings{client: is.client, users: is.users}
+}
+
+// release marks the client of the snapshot as no longer in use by the request
+func (is *instanceSettings) release() {
+	is.users.Done()
 }
 
+// getInstance returns a snapshot of the instance with its current client, so that a request uses the same client throughout.
+// The snapshot must be released once the request is complete
 func getInstance(ctx context.Context, im instancemgmt.InstanceManager, pCtx backend.PluginContext) (*instanceSettings, error) {
 	instance, err := im.Get(ctx, pCtx)
 	if err != nil {
 		return nil, err
 	}
-	return instance.(*instanceSettings), nil
+	return instance.(*instanceSettings).acquire(), nil
 }
 
 func getInstanceFromRequest(ctx context.Context, im instancemgmt.InstanceManager, req *http.Request) (*instanceSettings, error) {
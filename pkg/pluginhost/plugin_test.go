@@ -0,0 +1,73 @@
+package pluginhost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/infinity"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/mercury"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/models"
+)
+
+func TestSecretReferencesRefresh(t *testing.T) {
+	t.Run("should dispose the refreshed client when the instance is disposed during the refresh", func(t *testing.T) {
+		t.Setenv(models.EnvSecretReferenceAllowedEnvPrefixes, "INFINITY_")
+		t.Setenv(models.EnvSecretReferenceRefreshInterval, "10ms")
+		t.Setenv("INFINITY_TEST_TOKEN", "token-1")
+		started, release := make(chan struct{}), make(chan struct{})
+		var refreshed *infinity.Client
+		newClient = func(ctx context.Context, settings models.InfinitySettings) (*infinity.Client, error) {
+			client, err := infinity.NewClient(ctx, settings)
+			if settings.BearerToken != "token-1" {
+				refreshed = client
+				close(started)
+				<-release
+			}
+			return client, err
+		}
+		t.Cleanup(func() { newClient = infinity.NewClient })
+		// the clients of the persistent zcap adapter have a mercury pool which fails the requests once the client is disposed
+		instance, err := newDataSourceInstance(context.Background(), backend.DataSourceInstanceSettings{
+			JSONData:                []byte(`{ "auth_method": "zcap", "zcap": { "persistentAdapter": true } }`),
+			DecryptedSecureJSONData: map[string]string{"bearerToken": "env:INFINITY_TEST_TOKEN"},
+		})
+		require.Nil(t, err)
+		is := instance.(*instanceSettings)
+		initial := is.client
+		// the token is rotated until the refresh picks it up, as the refresh reads the initial secrets in the background
+	rotate:
+		for i := 2; ; i++ {
+			require.Nil(t, os.Setenv("INFINITY_TEST_TOKEN", fmt.Sprintf("token-%d", i)))
+			select {
+			case <-started:
+				break rotate
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+		disposed := make(chan struct{})
+		go func() {
+			is.Dispose()
+			close(disposed)
+		}()
+		select {
+		case <-disposed:
+			t.Fatal("dispose should wait for the in-flight refresh")
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(release)
+		<-disposed
+		require.Same(t, initial, is.client)
+		isDisposed := func(client *infinity.Client) bool {
+			_, err := client.MercuryPool.Request(context.Background(), mercury.OperationRequest, "https://foo.com", mercury.RequestOptions{})
+			return errors.Is(err, mercury.ErrPoolClosed)
+		}
+		require.True(t, isDisposed(refreshed))
+		require.Eventually(t, func() bool { return isDisposed(initial) }, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,146 @@
+package zcap
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// multicodec prefix of ed25519 private keys (ed25519-priv)
+var ed25519PrivateKeyPrefix = []byte{0x80, 0x26}
+
+// Key is the signing key used to invoke the capabilities
+type Key struct {
+	ID         string
+	PrivateKey ed25519.PrivateKey
+}
+
+// exportedKey is the JSON representation of Ed25519VerificationKey2020 keys as exported by the digitalbazaar libraries
+type exportedKey struct {
+	ID                  string `json:"id"`
+	Controller          string `json:"controller"`
+	PrivateKeyMultibase string `json:"privateKeyMultibase"`
+}
+
+/*
+ * ParseKey parses the ed25519 private key used to sign the capability invocations.
+ *
+ * Supported formats:
+ *		JSON exported Ed25519VerificationKey2020 key pair with `id` and `privateKeyMultibase`
+ *		PEM encoded PKCS#8 private key
+ *		multibase (base58btc, `z` prefixed) encoded private key or seed
+ *		base64 encoded private key or seed
+ */
+func ParseKey(input string, keyID string) (*Key, error) {
+	input = strings.TrimSpace(strings.ReplaceAll(input, "\\n", "\n"))
+	if input == "" {
+		return nil, errors.New("empty zcap private key")
+	}
+	key := &Key{ID: keyID}
+	switch {
+	case strings.HasPrefix(input, "{"):
+		exported := exportedKey{}
+		if err := json.Unmarshal([]byte(input), &exported); err != nil {
+			return nil, fmt.Errorf("invalid zcap key json. %w", err)
+		}
+		if key.ID == "" {
+			key.ID = exported.ID
+			if key.ID != "" && strings.HasPrefix(key.ID, "#") {
+				key.ID = exported.Controller + key.ID
+			}
+		}
+		privateKey, err := parseMultibaseKey(exported.PrivateKeyMultibase)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey = privateKey
+	case strings.HasPrefix(input, "-----BEGIN"):
+		block, _ := pem.Decode([]byte(input))
+		if block == nil {
+			return nil, errors.New("invalid zcap PEM private key")
+		}
+		parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zcap PEM private key. %w", err)
+		}
+		privateKey, ok := parsedKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("zcap PEM private key is not an ed25519 key")
+		}
+		key.PrivateKey = privateKey
+	case strings.HasPrefix(input, "z"):
+		privateKey, err := parseMultibaseKey(input)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey = privateKey
+	default:
+		b, err := base64.StdEncoding.DecodeString(input)
+		if err != nil {
+			if b, err = base64.RawURLEncoding.DecodeString(input); err != nil {
+				return nil, errors.New("invalid zcap private key encoding")
+			}
+		}
+		privateKey, err := toPrivateKey(b)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey = privateKey
+	}
+	if key.ID == "" {
+		return nil, errors.New("invalid or empty zcap key id")
+	}
+	return key, nil
+}
+
+func parseMultibaseKey(input string) (ed25519.PrivateKey, error) {
+	if !strings.HasPrefix(input, "z") {
+		return nil, errors.New("invalid zcap multibase private key. only base58btc encoding is supported")
+	}
+	b, err := decodeBase58(strings.TrimPrefix(input, "z"))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 2 && b[0] == ed25519PrivateKeyPrefix[0] && b[1] == ed25519PrivateKeyPrefix[1] {
+		b = b[2:]
+	}
+	return toPrivateKey(b)
+}
+
+func toPrivateKey(b []byte) (ed25519.PrivateKey, error) {
+	switch len(b) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(b), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(b), nil
+	default:
+		return nil, fmt.Errorf("invalid zcap private key length %d", len(b))
+	}
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func decodeBase58(input string) ([]byte, error) {
+	result := big.NewInt(0)
+	radix := big.NewInt(58)
+	for _, r := range input {
+		index := strings.IndexRune(base58Alphabet, r)
+		if index < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", r)
+		}
+		result.Mul(result, radix)
+		result.Add(result, big.NewInt(int64(index)))
+	}
+	decoded := result.Bytes()
+	leadingZeros := 0
+	for leadingZeros < len(input) && input[leadingZeros] == base58Alphabet[0] {
+		leadingZeros++
+	}
+	return append(make([]byte, leadingZeros), decoded...), nil
+}
@@ -0,0 +1,204 @@
+package zcap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderCapabilityInvocation = "Capability-Invocation"
+	HeaderDigest               = "Digest"
+	HeaderAuthorization        = "Authorization"
+)
+
+const RootCapabilityPrefix = "urn:zcap:root:"
+
+const defaultExpiry = 10 * time.Minute
+
+/*
+ * Invoker signs HTTP requests with zCap (ZCAP-LD) capability invocations.
+ *
+ * The invocation is expressed as HTTP Signatures over the request target, host, the capability
+ * invocation header and, when the request has a body, the content type and digest headers.
+ * This is compatible with the `@digitalbazaar/http-signature-zcap-invoke` library used by mercury-client.
+ */
+type Invoker struct {
+	Key *Key
+	// Capability is the JSON of the delegated capability to invoke.
+	// When empty, the root capability of the invocation target is invoked.
+	Capability string
+	// InvocationTarget of the root capability. When empty, the request URL without query is used.
+	InvocationTarget string
+	// Action to invoke. When empty, `read` is used for GET/HEAD requests and `write` for the others.
+	Action    string
+	ExpiresIn time.Duration
+	Now       func() time.Time
+}
+
+// Sign adds the capability invocation, digest and authorization headers to the request
+func (i *Invoker) Sign(req *http.Request) error {
+	if i.Key == nil || len(i.Key.PrivateKey) != ed25519.PrivateKeySize {
+		return errors.New("invalid zcap signing key")
+	}
+	now := time.Now
+	if i.Now != nil {
+		now = i.Now
+	}
+	expiresIn := i.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = defaultExpiry
+	}
+	created := now().Unix()
+	expires := now().Add(expiresIn).Unix()
+	invocation, err := i.getCapabilityInvocationHeader(req)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(HeaderCapabilityInvocation, invocation)
+	headers := []string{"(key-id)", "(created)", "(expires)", "(request-target)", "host", "capability-invocation"}
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 {
+		req.Header.Set(HeaderDigest, GetDigest(body))
+		headers = append(headers, "content-type", "digest")
+	}
+	signingString := GetSigningString(req, headers, i.Key.ID, created, expires)
+	signature := ed25519.Sign(i.Key.PrivateKey, []byte(signingString))
+	req.Header.Set(HeaderAuthorization, fmt.Sprintf(`Signature keyId="%s",headers="%s",signature="%s",created="%d",expires="%d"`, i.Key.ID, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(signature), created, expires))
+	return nil
+}
+
+func (i *Invoker) getCapabilityInvocationHeader(req *http.Request) (string, error) {
+	action := i.Action
+	if action == "" {
+		action = "write"
+		if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == "" {
+			action = "read"
+		}
+	}
+	if strings.TrimSpace(i.Capability) == "" {
+		target := i.InvocationTarget
+		if target == "" {
+			u := *req.URL
+			u.RawQuery = ""
+			u.Fragment = ""
+			target = u.String()
+		}
+		return fmt.Sprintf(`zcap id="%s",action="%s"`, RootCapabilityID(target), action), nil
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(strings.TrimSpace(i.Capability))); err != nil {
+		return "", fmt.Errorf("error encoding zcap capability. %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("error encoding zcap capability. %w", err)
+	}
+	return fmt.Sprintf(`zcap capability="%s",action="%s"`, base64.RawURLEncoding.EncodeToString(buf.Bytes()), action), nil
+}
+
+// GetSigningString returns the HTTP Signatures signing string of the request for the given headers
+func GetSigningString(req *http.Request, headers []string, keyID string, created int64, expires int64) string {
+	lines := []string{}
+	for _, h := range headers {
+		switch h {
+		case "(key-id)":
+			lines = append(lines, "(key-id): "+keyID)
+		case "(created)":
+			lines = append(lines, "(created): "+strconv.FormatInt(created, 10))
+		case "(expires)":
+			lines = append(lines, "(expires): "+strconv.FormatInt(expires, 10))
+		case "(request-target)":
+			lines = append(lines, "(request-target): "+strings.ToLower(req.Method)+" "+req.URL.RequestURI())
+		case "host":
+			host := req.Host
+			if host == "" {
+				host = req.URL.Host
+			}
+			lines = append(lines, "host: "+host)
+		default:
+			lines = append(lines, h+": "+req.Header.Get(h))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// GetDigest returns the multihash (sha2-256) digest header value of the body encoded as base64url multibase
+func GetDigest(body []byte) string {
+	sum := sha256.Sum256(body)
+	multihash := append([]byte{0x12, 0x20}, sum[:]...)
+	return "mh=u" + base64.RawURLEncoding.EncodeToString(multihash)
+}
+
+// RootCapabilityID returns the id of the root capability for the given invocation target
+func RootCapabilityID(invocationTarget string) string {
+	return RootCapabilityPrefix + encodeURIComponent(invocationTarget)
+}
+
+// encodeURIComponent escapes the string the same way as the javascript encodeURIComponent
+func encodeURIComponent(input string) string {
+	var sb strings.Builder
+	for _, b := range []byte(input) {
+		if (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') || strings.IndexByte("-_.!~*'()", b) >= 0 {
+			sb.WriteByte(b)
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%%%02X", b))
+	}
+	return sb.String()
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }
+	return b, nil
+}
+
+// Transport is a http.RoundTripper which signs every request with the zCap invoker
+type Transport struct {
+	Invoker *Invoker
+	Base    http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Invoker == nil {
+		return nil, errors.New("invalid zcap invoker")
+	}
+	signedReq := req.Clone(req.Context())
+	if err := t.Invoker.Sign(signedReq); err != nil {
+		return nil, fmt.Errorf("error signing zcap invocation. %w", err)
+	}
+	return base.RoundTrip(signedReq)
+}
@@ -0,0 +1,108 @@
+package zcap_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yesoreyeram/grafana-infinity-datasource/pkg/zcap"
+)
+
+var testSeed = bytes.Repeat([]byte{7}, ed25519.SeedSize)
+
+func TestParseKey(t *testing.T) {
+	want := ed25519.NewKeyFromSeed(testSeed)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(want)
+	require.Nil(t, err)
+	tests := []struct {
+		name    string
+		input   string
+		keyID   string
+		wantID  string
+		wantErr bool
+	}{
+		{name: "base64 seed", input: base64.StdEncoding.EncodeToString(testSeed), keyID: "did:key:foo#bar", wantID: "did:key:foo#bar"},
+		{name: "base64 private key", input: base64.StdEncoding.EncodeToString(want), keyID: "did:key:foo#bar", wantID: "did:key:foo#bar"},
+		{name: "pem private key", input: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})), keyID: "did:key:foo#bar", wantID: "did:key:foo#bar"},
+		{name: "json exported key", input: `{"id":"#bar","controller":"did:key:foo","privateKeyMultibase":"` + encodeMultibase(append([]byte{0x80, 0x26}, want...)) + `"}`, wantID: "did:key:foo#bar"},
+		{name: "missing key id", input: base64.StdEncoding.EncodeToString(testSeed), wantErr: true},
+		{name: "invalid key length", input: base64.StdEncoding.EncodeToString([]byte("short")), keyID: "foo", wantErr: true},
+		{name: "empty key", input: "", keyID: "foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := zcap.ParseKey(tt.input, tt.keyID)
+			if tt.wantErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.wantID, key.ID)
+			assert.Equal(t, want, key.PrivateKey)
+		})
+	}
+}
+
+func TestRootCapabilityID(t *testing.T) {
+	assert.Equal(t, "urn:zcap:root:https%3A%2F%2Ffoo.com%2Fedvs%2Fz123", zcap.RootCapabilityID("https://foo.com/edvs/z123"))
+}
+
+func TestInvoker_Sign(t *testing.T) {
+	key := &zcap.Key{ID: "did:key:foo#bar", PrivateKey: ed25519.NewKeyFromSeed(testSeed)}
+	invoker := &zcap.Invoker{Key: key, Now: func() time.Time { return time.Unix(1700000000, 0) }}
+	req, err := http.NewRequest(http.MethodPost, "https://foo.com/edvs/z123/query?a=b", strings.NewReader(`{"foo":"bar"}`))
+	require.Nil(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	require.Nil(t, invoker.Sign(req))
+	assert.Equal(t, `zcap id="urn:zcap:root:https%3A%2F%2Ffoo.com%2Fedvs%2Fz123%2Fquery",action="write"`, req.Header.Get(zcap.HeaderCapabilityInvocation))
+	assert.Equal(t, zcap.GetDigest([]byte(`{"foo":"bar"}`)), req.Header.Get(zcap.HeaderDigest))
+	matches := regexp.MustCompile(`^Signature keyId="([^"]+)",headers="([^"]+)",signature="([^"]+)",created="(\d+)",expires="(\d+)"$`).FindStringSubmatch(req.Header.Get(zcap.HeaderAuthorization))
+	require.Len(t, matches, 6)
+	assert.Equal(t, "(key-id) (created) (expires) (request-target) host capability-invocation content-type digest", matches[2])
+	created, _ := strconv.ParseInt(matches[4], 10, 64)
+	expires, _ := strconv.ParseInt(matches[5], 10, 64)
+	assert.Equal(t, int64(1700000000), created)
+	assert.Equal(t, int64(1700000600), expires)
+	signature, err := base64.StdEncoding.DecodeString(matches[3])
+	require.Nil(t, err)
+	signingString := zcap.GetSigningString(req, strings.Split(matches[2], " "), matches[1], created, expires)
+	assert.True(t, ed25519.Verify(key.PrivateKey.Public().(ed25519.PublicKey), []byte(signingString), signature))
+}
+
+func encodeMultibase(input []byte) string {
+	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	digits := []byte{0}
+	for _, b := range input {
+		carry := int(b)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+	out := []byte{}
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+		out = append(out, alphabet[0])
+	}
+	for i := len(digits) - 1; i >= 0; i-- {
+		out = append(out, alphabet[digits[i]])
+	}
+	return "z" + string(out)
+}
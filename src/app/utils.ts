@@ -19,6 +19,10 @@ export const isBackendQuery = (
 ): query is Extract<InfinityQuery, ({ type: 'json' } | { type: 'csv' } | { type: 'tsv' } | { type: 'xml' } | { type: 'graphql' } | { type: 'html' }) & { parser: 'backend' }> =>
   query.type === 'transformations' || (isBackendQuerySupported(query) && query.parser === 'backend');
 
+export const isUQLOrGROQQuery = (
+  query: InfinityQuery
+): query is Extract<InfinityQuery, { type: 'uql' } | { type: 'groq' } | { parser: 'uql' } | { parser: 'groq' }> =>
+  query.type === 'uql' || query.type === 'groq' || (isBackendQuerySupported(query) && (query.parser === 'uql' || query.parser === 'groq'));
 export const isDataQuery = (query: InfinityQuery): query is InfinityQueryWithDataSource<any> => {
   switch (query.type) {
     case 'csv':
@@ -11,7 +11,7 @@ import { getTemplateVariablesFromResult, LegacyVariableProvider, migrateLegacyQu
 import { AnnotationsEditor } from './editors/annotation.editor';
 import { interpolateQuery, interpolateVariableQuery } from './interpolate';
 import { migrateQuery } from './migrate';
-import { isBackendQuery } from './app/utils';
+import { isBackendQuery, isDataQuery } from './app/utils';
 import { reportQuery, reportHealthCheck } from './utils/analytics';
 import type { InfinityInstanceSettings, InfinityOptions, InfinityQuery, MetricFindValue, VariableQuery } from './types';
 
@@ -141,6 +141,8 @@ export class Datasource extends DataSourceWithBackend<InfinityQuery, InfinityOpt
         const error = d.meta?.custom?.error;
         if (isBackendQuery(target)) {
           promises.push(Promise.resolve(d));
+        } else if (isDataQuery(target) && target.source === 'edv') {
+          promises.push(Promise.resolve(d));
         } else if (target.type === 'google-sheets') {
           promises.push(Promise.resolve(d));
         } else if (target.type === 'transformations') {
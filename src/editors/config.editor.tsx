@@ -5,6 +5,11 @@ import { InlineFormLabel, Input, Button, LinkButton, useTheme2, Collapse as Coll
 import { SecureFieldsEditor } from './../components/config/SecureFieldsEditor';
 import { AuthEditor } from './config/Auth';
 import { ProxyEditor } from './config/ProxyEditor';
+import { DNSEditor } from './config/DNSEditor';
+import { TransportEditor } from './config/TransportEditor';
+import { RateLimitsEditor } from './config/RateLimitsEditor';
+import { PaginationLimitsEditor } from './config/PaginationLimitsEditor';
+import { CircuitBreakerEditor } from './config/CircuitBreakerEditor';
 import { AllowedHostsEditor } from './config/AllowedHosts';
 import { GlobalQueryEditor } from './config/GlobalQueryEditor';
 import { ProvisioningScript } from './config/Provisioning';
@@ -13,6 +18,8 @@ import { URLEditor } from './config/URL';
 import { OpenAPIEditor } from './config/OpenAPI';
 import { ReferenceDataEditor } from './config/ReferenceData';
 import { CustomHealthCheckEditor } from './config/CustomHealthCheckEditor';
+import { SecretReferencesInfo } from './config/SecretReferences';
+import { HostProfilesEditor } from './config/HostProfiles';
 import type { DataSourcePluginOptionsEditorProps } from '@grafana/data/types';
 import type { InfinityOptions } from './../types';
 
@@ -107,6 +114,8 @@ export const NetworkEditor = (props: DataSourcePluginOptionsEditorProps<Infinity
       <div style={{ padding: '1px 10px' }}>
         <TLSConfigEditor options={options} onOptionsChange={onOptionsChange} hideTile={true} />
         <ProxyEditor options={options} onOptionsChange={onOptionsChange} />
+        <DNSEditor options={options} onOptionsChange={onOptionsChange} />
+        <TransportEditor options={options} onOptionsChange={onOptionsChange} />
       </div>
     </>
   );
@@ -117,6 +126,18 @@ export const SecurityEditor = (props: DataSourcePluginOptionsEditorProps<Infinit
   return (
     <>
       <AllowedHostsEditor options={options} onOptionsChange={onOptionsChange} />
+      <SecretReferencesInfo />
+    </>
+  );
+};
+
+export const LimitsEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  return (
+    <>
+      <RateLimitsEditor options={options} onOptionsChange={onOptionsChange} />
+      <PaginationLimitsEditor options={options} onOptionsChange={onOptionsChange} />
+      <CircuitBreakerEditor options={options} onOptionsChange={onOptionsChange} />
     </>
   );
 };
@@ -143,8 +164,10 @@ const config_sections: Array<{ value: string; label: string; component?: (props:
   { value: 'main', label: 'Main' },
   { value: 'auth', label: 'Authentication' },
   { value: 'headers_and_params', label: 'Headers & URL params' },
+  { value: 'host_profiles', label: 'Host profiles' },
   { value: 'network', label: 'Network' },
   { value: 'security', label: 'Security' },
+  { value: 'limits', label: 'Limits' },
   { value: 'health_check', label: 'Health check' },
   { value: 'reference_data', label: 'Reference data' },
   { value: 'global_queries', label: 'Global queries' },
@@ -221,10 +244,14 @@ export const InfinityConfigEditor = (props: DataSourcePluginOptionsEditorProps<I
             <AuthEditor options={options} onOptionsChange={onOptionsChange} />
           ) : activeTab === 'headers_and_params' ? (
             <HeadersEditor options={options} onOptionsChange={onOptionsChange} />
+          ) : activeTab === 'host_profiles' ? (
+            <HostProfilesEditor options={options} onOptionsChange={onOptionsChange} />
           ) : activeTab === 'network' ? (
             <NetworkEditor options={options} onOptionsChange={onOptionsChange} />
           ) : activeTab === 'security' ? (
             <SecurityEditor options={options} onOptionsChange={onOptionsChange} />
+          ) : activeTab === 'limits' ? (
+            <LimitsEditor options={options} onOptionsChange={onOptionsChange} />
           ) : activeTab === 'global_queries' ? (
             <GlobalQueryEditor options={options} onOptionsChange={onOptionsChange} />
           ) : activeTab === 'reference_data' ? (
@@ -12,8 +12,12 @@ export const AllowedHostsEditor = ({ options, onOptionsChange }: AllowedHostsEdi
   return (
     <>
       <p>For the enhanced security, enter list of allowed hosts in this section. The host URLs can include path and the URLs are case sensitive</p>
+      <p>
+        Unix domain sockets are queried only when allowed here. Use the format <code>unix://&lt;socket path&gt;:&lt;request path prefix&gt;</code>, for example{' '}
+        <code>unix:///var/run/docker.sock:/containers</code>. Redirects to the unix sockets are never followed.
+      </p>
       <div className="gf-form">
-        <InlineFormLabel width={10} tooltip="List of allowed host names. Enter the base URL names. ex: https://foo.com">
+        <InlineFormLabel width={10} tooltip="List of allowed host names. Enter the base URL names. ex: https://foo.com or unix:///var/run/docker.sock:/containers">
           Allowed hosts
         </InlineFormLabel>
         <TagsInput
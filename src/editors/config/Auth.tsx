@@ -3,8 +3,11 @@ import { onUpdateDatasourceSecureJsonDataOption } from '@grafana/data';
 import { Icon, InlineFormLabel, LegacyForms, RadioButtonGroup, Select, useTheme2 } from '@grafana/ui';
 import React, { useState } from 'react';
 import { AllowedHostsEditor } from './AllowedHosts';
+import { AzureADInputsEditor } from './AzureADInput';
+import { HMACInputsEditor } from './HMACInput';
 import { OAuthInputsEditor } from './OAuthInput';
 import { OthersAuthentication } from './OtherAuthProviders';
+import { ZCapInputsEditor } from './ZCapInput';
 import { AWSRegions } from './../../constants';
 import type { APIKeyType, AuthType, InfinityOptions, InfinitySecureOptions } from './../../types';
 import type { DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data/types';
@@ -20,6 +23,8 @@ const authTypes: Array<SelectableValue<AuthType | 'others'> & { logo?: string }>
   { value: 'aws', label: 'AWS', logo: '/public/plugins/yesoreyeram-infinity-datasource/img/aws.jpg' },
   { value: 'zcap', label: 'ZCAP' },
   { value: 'azureBlob', label: 'Azure Blob' },
+  { value: 'azureAD', label: 'Azure AD' },
+  { value: 'hmac', label: 'HMAC Signature' },
   { value: 'others', label: 'Other Auth Providers' },
 ];
 
@@ -80,6 +85,8 @@ export const AuthEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOpt
       case 'zcap':
       case 'aws':
       case 'azureBlob':
+      case 'azureAD':
+      case 'hmac':
       case 'oauth2':
       case 'none':
       default:
@@ -92,9 +99,6 @@ export const AuthEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOpt
   const onAPIKeyKeyChange = (apiKeyKey: string) => {
     onOptionsChange({ ...options, jsonData: { ...options.jsonData, apiKeyKey } });
   };
-  const onZCapPathChange = (zcapJsonPath: string) => {
-    onOptionsChange({ ...options, jsonData: { ...options.jsonData, zcapJsonPath} });
-  };
   const onAwsRegionChange = (region: string) => {
     onOptionsChange({ ...options, jsonData: { ...options.jsonData, aws: { ...options.jsonData?.aws, region } } });
   };
@@ -172,23 +176,7 @@ export const AuthEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOpt
                 </div>
               </>
             )}
-      {authType === 'zcap' && (
-        <>
-          <div className="gf-form">
-            <FormField
-              labelWidth={10}
-              inputWidth={12}
-              required
-              value={props.options.jsonData.zcapJsonPath || ''}
-              onChange={(e) => onZCapPathChange(e.currentTarget.value)}
-              label="Resource Target"
-              aria-label="Target URL"
-              placeholder="Target Resource URL"
-              tooltip="Input the url for the resource"
-            />
-          </div>
-        </>
-      )}
+            {authType === 'zcap' && <ZCapInputsEditor {...props} />}
             {authType === 'bearerToken' && (
               <>
                 <div className="gf-form">
@@ -298,6 +286,22 @@ export const AuthEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOpt
               </>
             )}
             {authType === 'oauth2' && <OAuthInputsEditor {...props} />}
+            {authType === 'hmac' && <HMACInputsEditor {...props} />}
+            {authType === 'azureAD' && (
+              <>
+                <AzureADInputsEditor {...props} />
+                <div className="gf-form">
+                  <FormField
+                    label="Storage account name"
+                    placeholder="(optional) storage account name"
+                    tooltip={'azure blob storage account name. When set, the token is also used for azure blob storage queries'}
+                    labelWidth={10}
+                    value={props.options.jsonData?.azureBlobAccountName || ''}
+                    onChange={(e) => onAzureBlogAccountChange(e.currentTarget.value)}
+                  ></FormField>
+                </div>
+              </>
+            )}
             {authType === 'azureBlob' && (
               <>
                 <div className="gf-form">
@@ -0,0 +1,124 @@
+import { onUpdateDatasourceSecureJsonDataOption } from '@grafana/data';
+import { InlineFormLabel, Input, LegacyForms, RadioButtonGroup, Select } from '@grafana/ui';
+import React from 'react';
+import type { AzureADAuthType, AzureADCloud, AzureADProps, InfinityOptions, InfinitySecureOptions } from './../../types';
+import type { DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data/types';
+
+const azureADAuthTypes: Array<SelectableValue<AzureADAuthType>> = [
+  { value: 'clientSecret', label: 'Client Secret' },
+  { value: 'clientCertificate', label: 'Client Certificate' },
+];
+
+const azureADClouds: Array<SelectableValue<AzureADCloud>> = [
+  { value: 'AzurePublic', label: 'Azure' },
+  { value: 'AzureChina', label: 'Azure China' },
+  { value: 'AzureUSGovernment', label: 'Azure US Government' },
+];
+
+export const AzureADInputsEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const { secureJsonFields } = options;
+  const secureJsonData = (options.secureJsonData || {}) as InfinitySecureOptions;
+  const azureAD: AzureADProps = options?.jsonData?.azureAD || {};
+  const onAzureADPropsChange = <T extends keyof AzureADProps, V extends AzureADProps[T]>(key: T, value: V) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, azureAD: { ...azureAD, [key]: value } } });
+  };
+  const onResetSecret = (key: keyof InfinitySecureOptions) => {
+    onOptionsChange({
+      ...options,
+      secureJsonFields: { ...options.secureJsonFields, [key]: false },
+      secureJsonData: { ...options.secureJsonData, [key]: '' },
+    });
+  };
+  return (
+    <>
+      <div className="gf-form">
+        <InlineFormLabel width={10} tooltip="Credential used to request tokens from Azure AD">
+          Auth Type
+        </InlineFormLabel>
+        <RadioButtonGroup<AzureADAuthType>
+          options={azureADAuthTypes}
+          onChange={(v) => onAzureADPropsChange('authType', v)}
+          value={azureAD.authType || 'clientSecret'}
+        ></RadioButtonGroup>
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10}>Cloud</InlineFormLabel>
+        <Select<AzureADCloud> width={30} options={azureADClouds} onChange={(v) => onAzureADPropsChange('cloud', v.value)} value={azureAD.cloud || 'AzurePublic'} />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10} tooltip="Optional. Overrides the authority host of the selected cloud">
+          Authority Host
+        </InlineFormLabel>
+        <Input onChange={(v) => onAzureADPropsChange('authorityHost', v.currentTarget.value)} value={azureAD.authorityHost} width={30} placeholder={'(optional) authority host'} />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10}>Tenant ID</InlineFormLabel>
+        <Input onChange={(v) => onAzureADPropsChange('tenantId', v.currentTarget.value)} value={azureAD.tenantId} width={30} placeholder={'Tenant ID'} />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10}>Client ID</InlineFormLabel>
+        <Input onChange={(v) => onAzureADPropsChange('clientId', v.currentTarget.value)} value={azureAD.clientId} width={30} placeholder={'Client ID'} />
+      </div>
+      {(azureAD.authType === 'clientSecret' || !azureAD.authType) && (
+        <div className="gf-form">
+          <LegacyForms.SecretFormField
+            labelWidth={10}
+            inputWidth={15}
+            required
+            value={secureJsonData.azureADClientSecret || ''}
+            isConfigured={(secureJsonFields && secureJsonFields.azureADClientSecret) as boolean}
+            onReset={() => onResetSecret('azureADClientSecret')}
+            onChange={onUpdateDatasourceSecureJsonDataOption(props, 'azureADClientSecret')}
+            label="Client Secret"
+            aria-label="azure ad client secret"
+            placeholder="Client secret"
+          />
+        </div>
+      )}
+      {azureAD.authType === 'clientCertificate' && (
+        <>
+          <div className="gf-form">
+            <LegacyForms.SecretFormField
+              labelWidth={10}
+              inputWidth={15}
+              required
+              value={secureJsonData.azureADClientCertificate || ''}
+              tooltip="PEM encoded certificate and private key"
+              isConfigured={(secureJsonFields && secureJsonFields.azureADClientCertificate) as boolean}
+              onReset={() => onResetSecret('azureADClientCertificate')}
+              onChange={onUpdateDatasourceSecureJsonDataOption(props, 'azureADClientCertificate')}
+              label="Certificate"
+              aria-label="azure ad client certificate"
+              placeholder="Client certificate"
+            />
+          </div>
+          <div className="gf-form">
+            <LegacyForms.SecretFormField
+              labelWidth={10}
+              inputWidth={15}
+              value={secureJsonData.azureADClientCertificatePassword || ''}
+              isConfigured={(secureJsonFields && secureJsonFields.azureADClientCertificatePassword) as boolean}
+              onReset={() => onResetSecret('azureADClientCertificatePassword')}
+              onChange={onUpdateDatasourceSecureJsonDataOption(props, 'azureADClientCertificatePassword')}
+              label="Password"
+              aria-label="azure ad client certificate password"
+              placeholder="(optional) certificate password"
+            />
+          </div>
+        </>
+      )}
+      <div className="gf-form">
+        <InlineFormLabel width={10} tooltip="Scopes of the token. Required unless only azure blob storage is queried. Enter comma separated values">
+          Scopes
+        </InlineFormLabel>
+        <Input
+          onChange={(v) => onAzureADPropsChange('scopes', (v.currentTarget.value || '').split(','))}
+          value={(azureAD.scopes || []).join(',')}
+          width={30}
+          placeholder={'Comma separated values of scopes'}
+        />
+      </div>
+    </>
+  );
+};
@@ -0,0 +1,84 @@
+import React from 'react';
+import { InlineLabel, InlineSwitch, Input } from '@grafana/ui';
+import type { DataSourcePluginOptionsEditorProps } from '@grafana/data/types';
+import type { CircuitBreakerProps, InfinityOptions } from './../../types';
+
+export const CircuitBreakerEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const { jsonData } = options;
+  const circuitBreaker: CircuitBreakerProps = jsonData?.circuitBreaker || {};
+  const onCircuitBreakerChange = <T extends keyof CircuitBreakerProps, V extends CircuitBreakerProps[T]>(key: T, value: V) => {
+    onOptionsChange({ ...options, jsonData: { ...jsonData, circuitBreaker: { ...circuitBreaker, [key]: value } } });
+  };
+  return (
+    <>
+      <h5>Circuit breaker</h5>
+      <p>
+        Stops sending the requests to a host after its consecutive failures. The queries to the host fail fast until the open duration is elapsed and then a single probe request decides
+        whether the host is healthy again. Connection errors and the server errors (5xx) are counted as failures.
+      </p>
+      <div className="gf-form">
+        <InlineLabel width={20}>Enable</InlineLabel>
+        <InlineSwitch value={circuitBreaker.enabled || false} onChange={(e) => onCircuitBreakerChange('enabled', e.currentTarget.checked)} />
+      </div>
+      {circuitBreaker.enabled && (
+        <>
+          <div className="gf-form">
+            <InlineLabel width={20} tooltip="Number of consecutive failures after which the circuit opens. Defaults to 5">
+              Failure threshold
+            </InlineLabel>
+            <Input
+              type="number"
+              min={0}
+              width={20}
+              value={circuitBreaker.failureThreshold}
+              placeholder="5"
+              onChange={(e) => onCircuitBreakerChange('failureThreshold', e.currentTarget.valueAsNumber || undefined)}
+            />
+          </div>
+          <div className="gf-form">
+            <InlineLabel width={20} tooltip="Seconds the circuit stays open before the probe request. Defaults to 30">
+              Open duration
+            </InlineLabel>
+            <Input
+              type="number"
+              min={0}
+              width={20}
+              value={circuitBreaker.openDurationInSeconds}
+              placeholder="30"
+              onChange={(e) => onCircuitBreakerChange('openDurationInSeconds', e.currentTarget.valueAsNumber || undefined)}
+            />
+          </div>
+        </>
+      )}
+      <h5>Serve stale on error</h5>
+      <p>
+        Serves the last successful response of the query with a warning when the upstream is unreachable, responds with a server error (5xx) or its circuit is open. Client errors such as
+        401 and 404 are never hidden. Not available when the user identity is forwarded, as the responses of one user must not be served to the others.
+      </p>
+      <div className="gf-form">
+        <InlineLabel width={20}>Enable</InlineLabel>
+        <InlineSwitch
+          value={jsonData?.serveStaleOnError || false}
+          disabled={jsonData?.oauthPassThru}
+          onChange={(e) => onOptionsChange({ ...options, jsonData: { ...jsonData, serveStaleOnError: e.currentTarget.checked } })}
+        />
+      </div>
+      {jsonData?.serveStaleOnError && (
+        <div className="gf-form">
+          <InlineLabel width={20} tooltip="Responses older than this are not served. Leave blank to serve the responses of any age">
+            Max age in seconds
+          </InlineLabel>
+          <Input
+            type="number"
+            min={0}
+            width={20}
+            value={jsonData?.staleMaxAgeInSeconds}
+            placeholder="(optional) max age"
+            onChange={(e) => onOptionsChange({ ...options, jsonData: { ...jsonData, staleMaxAgeInSeconds: e.currentTarget.valueAsNumber || undefined } })}
+          />
+        </div>
+      )}
+    </>
+  );
+};
@@ -0,0 +1,44 @@
+import React from 'react';
+import { Button, InlineLabel, Input } from '@grafana/ui';
+import type { DataSourcePluginOptionsEditorProps } from '@grafana/data/types';
+import type { DNSOverride, InfinityOptions } from './../../types';
+
+export const DNSEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const { jsonData } = options;
+  const dnsOverrides = jsonData?.dnsOverrides || [];
+  const onDNSOverridesChange = (dnsOverrides: DNSOverride[]) => {
+    onOptionsChange({ ...options, jsonData: { ...jsonData, dnsOverrides } });
+  };
+  const onDNSOverrideChange = <T extends keyof DNSOverride>(index: number, key: T, value: DNSOverride[T]) => {
+    onDNSOverridesChange(dnsOverrides.map((override, i) => (i === index ? { ...override, [key]: value } : override)));
+  };
+  return (
+    <>
+      <div className="gf-form">
+        <InlineLabel width={20} tooltip="Optional. IP address of the dns server used to resolve the hosts instead of the system resolver. Port 53 is used when not specified">
+          DNS Server
+        </InlineLabel>
+        <Input
+          value={jsonData?.dnsServer || ''}
+          placeholder="(optional) 10.0.0.2:53"
+          onChange={(e) => onOptionsChange({ ...options, jsonData: { ...jsonData, dnsServer: e.currentTarget.value } })}
+        />
+      </div>
+      {dnsOverrides.map((override, index) => (
+        <div className="gf-form" key={index}>
+          <InlineLabel width={20} tooltip="Connects to the ip address instead of resolving the host, similar to the curl --resolve option. The certificate of the host is still verified. Empty port matches all the ports of the host">
+            DNS Override {index + 1}
+          </InlineLabel>
+          <Input value={override.host} width={30} placeholder="host" onChange={(e) => onDNSOverrideChange(index, 'host', e.currentTarget.value)} />
+          <Input value={override.port || ''} width={12} placeholder="(optional) port" onChange={(e) => onDNSOverrideChange(index, 'port', e.currentTarget.value)} />
+          <Input value={override.ip} width={30} placeholder="ip address" onChange={(e) => onDNSOverrideChange(index, 'ip', e.currentTarget.value)} />
+          <Button variant="secondary" fill="text" icon="trash-alt" aria-label={`remove dns override ${index + 1}`} onClick={() => onDNSOverridesChange(dnsOverrides.filter((_, i) => i !== index))} />
+        </div>
+      ))}
+      <Button variant="secondary" size="sm" icon="plus" onClick={() => onDNSOverridesChange([...dnsOverrides, { host: '', ip: '' }])}>
+        Add DNS override
+      </Button>
+    </>
+  );
+};
@@ -0,0 +1,118 @@
+import { onUpdateDatasourceSecureJsonDataOption } from '@grafana/data';
+import { InlineFormLabel, Input, LegacyForms, RadioButtonGroup, Select, TextArea } from '@grafana/ui';
+import React from 'react';
+import type { HMACProps, InfinityOptions, InfinitySecureOptions } from './../../types';
+import type { DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data/types';
+
+const hmacAlgorithms: Array<SelectableValue<HMACProps['algorithm']>> = [
+  { value: 'sha1', label: 'SHA1' },
+  { value: 'sha256', label: 'SHA256' },
+  { value: 'sha512', label: 'SHA512' },
+];
+
+const hmacTimestampFormats: Array<SelectableValue<HMACProps['timestampFormat']>> = [
+  { value: 'unix', label: 'Unix (seconds)' },
+  { value: 'unix_ms', label: 'Unix (milliseconds)' },
+  { value: 'rfc3339', label: 'RFC3339' },
+  { value: 'rfc1123', label: 'RFC1123' },
+];
+
+export const HMACInputsEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const { secureJsonFields } = options;
+  const secureJsonData = (options.secureJsonData || {}) as InfinitySecureOptions;
+  const hmac: HMACProps = options?.jsonData?.hmac || {};
+  const onHMACPropsChange = <T extends keyof HMACProps, V extends HMACProps[T]>(key: T, value: V) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, hmac: { ...hmac, [key]: value } } });
+  };
+  const onResetSecret = () => {
+    onOptionsChange({
+      ...options,
+      secureJsonFields: { ...options.secureJsonFields, hmacSecret: false },
+      secureJsonData: { ...options.secureJsonData, hmacSecret: '' },
+    });
+  };
+  return (
+    <>
+      <div className="gf-form">
+        <LegacyForms.SecretFormField
+          labelWidth={10}
+          inputWidth={15}
+          required
+          value={secureJsonData.hmacSecret || ''}
+          isConfigured={(secureJsonFields && secureJsonFields.hmacSecret) as boolean}
+          onReset={onResetSecret}
+          onChange={onUpdateDatasourceSecureJsonDataOption(props, 'hmacSecret')}
+          label="Secret"
+          aria-label="hmac secret"
+          placeholder="hmac secret"
+        />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10} tooltip="Encoding of the secret above">
+          Secret Encoding
+        </InlineFormLabel>
+        <RadioButtonGroup<NonNullable<HMACProps['secretEncoding']>>
+          options={[
+            { value: '', label: 'Plain text' },
+            { value: 'base64', label: 'Base64' },
+          ]}
+          onChange={(v) => onHMACPropsChange('secretEncoding', v)}
+          value={hmac.secretEncoding || ''}
+        ></RadioButtonGroup>
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10}>Algorithm</InlineFormLabel>
+        <Select width={30} options={hmacAlgorithms} onChange={(v) => onHMACPropsChange('algorithm', v.value)} value={hmac.algorithm || 'sha256'} />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10} tooltip="Encoding of the signature">
+          Encoding
+        </InlineFormLabel>
+        <RadioButtonGroup<NonNullable<HMACProps['encoding']>>
+          options={[
+            { value: 'hex', label: 'Hex' },
+            { value: 'base64', label: 'Base64' },
+          ]}
+          onChange={(v) => onHMACPropsChange('encoding', v)}
+          value={hmac.encoding || 'hex'}
+        ></RadioButtonGroup>
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel
+          width={10}
+          tooltip="Template of the signed message. Supported placeholders are ${method}, ${path}, ${query}, ${uri}, ${host}, ${timestamp}, ${body}, ${bodyHash} and ${bodyHashBase64}"
+        >
+          String to sign
+        </InlineFormLabel>
+        <TextArea
+          rows={4}
+          cols={40}
+          onChange={(v) => onHMACPropsChange('stringToSign', v.currentTarget.value)}
+          value={hmac.stringToSign}
+          placeholder={'${method}\\n${path}\\n${timestamp}\\n${bodyHash}'}
+        />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10}>Timestamp Format</InlineFormLabel>
+        <Select width={30} options={hmacTimestampFormats} onChange={(v) => onHMACPropsChange('timestampFormat', v.value)} value={hmac.timestampFormat || 'unix'} />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10} tooltip="Optional. Header used to send the timestamp">
+          Timestamp Header
+        </InlineFormLabel>
+        <Input onChange={(v) => onHMACPropsChange('timestampHeader', v.currentTarget.value)} value={hmac.timestampHeader} width={30} placeholder={'(optional) X-Timestamp'} />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10}>Signature Header</InlineFormLabel>
+        <Input onChange={(v) => onHMACPropsChange('signatureHeader', v.currentTarget.value)} value={hmac.signatureHeader} width={30} placeholder={'X-Signature'} />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10} tooltip="Optional. Prefix added before the signature value">
+          Signature Prefix
+        </InlineFormLabel>
+        <Input onChange={(v) => onHMACPropsChange('signaturePrefix', v.currentTarget.value)} value={hmac.signaturePrefix} width={30} placeholder={'(optional) signature prefix'} />
+      </div>
+    </>
+  );
+};
@@ -0,0 +1,166 @@
+import { Button, InlineFormLabel, Input, LegacyForms, RadioButtonGroup } from '@grafana/ui';
+import React from 'react';
+import type { APIKeyType, AuthType, HostProfile, InfinityOptions } from './../../types';
+import type { DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data/types';
+
+const profileAuthTypes: Array<SelectableValue<AuthType>> = [
+  { value: 'none', label: 'No Auth' },
+  { value: 'basicAuth', label: 'Basic Authentication' },
+  { value: 'bearerToken', label: 'Bearer Token' },
+  { value: 'apiKey', label: 'API Key Value pair' },
+];
+
+type HostProfileEditorProps = {
+  profile: HostProfile;
+  index: number;
+  canRemove: boolean;
+  onRemove: () => void;
+} & DataSourcePluginOptionsEditorProps<InfinityOptions>;
+
+const HostProfileEditor = (props: HostProfileEditorProps) => {
+  const { options, onOptionsChange, profile, index, canRemove, onRemove } = props;
+  const secureJsonFields = (options.secureJsonFields || {}) as Record<string, boolean>;
+  const secureJsonData = (options.secureJsonData || {}) as Record<string, string>;
+  const secretPrefix = `hostProfile${index + 1}.`;
+  const onProfileChange = <T extends keyof HostProfile, V extends HostProfile[T]>(key: T, value: V) => {
+    const hostProfiles = [...(options.jsonData.hostProfiles || [])];
+    hostProfiles[index] = { ...profile, [key]: value };
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, hostProfiles } });
+  };
+  const onSecretChange = (key: string, value: string) => {
+    onOptionsChange({ ...options, secureJsonData: { ...options.secureJsonData, [secretPrefix + key]: value } });
+  };
+  const onSecretReset = (key: string) => {
+    onOptionsChange({
+      ...options,
+      secureJsonFields: { ...options.secureJsonFields, [secretPrefix + key]: false },
+      secureJsonData: { ...options.secureJsonData, [secretPrefix + key]: '' },
+    });
+  };
+  const renderSecretField = (secretKey: string, label: string) => (
+    <div className="gf-form">
+      <LegacyForms.SecretFormField
+        labelWidth={10}
+        inputWidth={12}
+        required
+        value={secureJsonData[secretPrefix + secretKey] || ''}
+        isConfigured={secureJsonFields[secretPrefix + secretKey]}
+        onReset={() => onSecretReset(secretKey)}
+        onChange={(e) => onSecretChange(secretKey, e.currentTarget.value)}
+        label={label}
+        aria-label={`host profile ${index + 1} ${label}`}
+        placeholder={label}
+      />
+    </div>
+  );
+  const authMethod = profile.auth_method || 'none';
+  return (
+    <div style={{ marginBlockEnd: '20px' }}>
+      <h6>
+        Profile {index + 1}
+        {canRemove && (
+          <Button variant="secondary" fill="text" size="sm" icon="trash-alt" onClick={onRemove} style={{ marginInlineStart: '10px' }}>
+            Remove
+          </Button>
+        )}
+      </h6>
+      <div className="gf-form">
+        <InlineFormLabel width={10}>Name</InlineFormLabel>
+        <Input value={profile.name || ''} width={30} placeholder="profile name" onChange={(e) => onProfileChange('name', e.currentTarget.value)} />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel
+          width={10}
+          tooltip="The profile is used for the URLs with the same scheme, host and port as the prefix whose path is the path of the prefix or below it. ex: https://foo.com/api matches https://foo.com/api/users but not https://foo.com/apis"
+        >
+          URL prefix
+        </InlineFormLabel>
+        <Input value={profile.urlPrefix || ''} width={30} placeholder="https://foo.com/api" onChange={(e) => onProfileChange('urlPrefix', e.currentTarget.value)} />
+      </div>
+      <div className="gf-form">
+        <InlineFormLabel width={10}>Auth type</InlineFormLabel>
+        <RadioButtonGroup<AuthType>
+          options={profileAuthTypes}
+          value={authMethod}
+          onChange={(v = 'none') => {
+            const hostProfiles = [...(options.jsonData.hostProfiles || [])];
+            hostProfiles[index] = { ...profile, auth_method: v, basicAuth: v === 'basicAuth' };
+            onOptionsChange({ ...options, jsonData: { ...options.jsonData, hostProfiles } });
+          }}
+        />
+      </div>
+      {authMethod === 'basicAuth' && (
+        <>
+          <div className="gf-form">
+            <InlineFormLabel width={10}>User Name</InlineFormLabel>
+            <Input value={profile.basicAuthUser || ''} width={30} placeholder="username" onChange={(e) => onProfileChange('basicAuthUser', e.currentTarget.value)} />
+          </div>
+          {renderSecretField('basicAuthPassword', 'Password')}
+        </>
+      )}
+      {authMethod === 'bearerToken' && renderSecretField('bearerToken', 'Bearer token')}
+      {authMethod === 'apiKey' && (
+        <>
+          <div className="gf-form">
+            <InlineFormLabel width={10}>Key</InlineFormLabel>
+            <Input value={profile.apiKeyKey || ''} width={30} placeholder="api key key" onChange={(e) => onProfileChange('apiKeyKey', e.currentTarget.value)} />
+          </div>
+          {renderSecretField('apiKeyValue', 'Value')}
+          <div className="gf-form">
+            <InlineFormLabel width={10}>Add to</InlineFormLabel>
+            <RadioButtonGroup<APIKeyType>
+              options={[
+                { value: 'header', label: 'Header' },
+                { value: 'query', label: 'Query Param' },
+              ]}
+              value={profile.apiKeyType || 'header'}
+              onChange={(v = 'header') => onProfileChange('apiKeyType', v)}
+            />
+          </div>
+        </>
+      )}
+    </div>
+  );
+};
+
+export const HostProfilesEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const hostProfiles = options.jsonData.hostProfiles || [];
+  const onAddProfile = () => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, hostProfiles: [...hostProfiles, { name: `profile${hostProfiles.length + 1}`, auth_method: 'none' }] } });
+  };
+  const onRemoveLastProfile = () => {
+    const secretPrefix = `hostProfile${hostProfiles.length}.`;
+    const secureJsonFields = { ...options.secureJsonFields };
+    const secureJsonData = { ...(options.secureJsonData || {}) } as Record<string, string>;
+    Object.keys(secureJsonFields)
+      .filter((key) => key.startsWith(secretPrefix))
+      .forEach((key) => {
+        secureJsonFields[key] = false;
+        secureJsonData[key] = '';
+      });
+    onOptionsChange({ ...options, secureJsonFields, secureJsonData, jsonData: { ...options.jsonData, hostProfiles: hostProfiles.slice(0, -1) } });
+  };
+  return (
+    <>
+      <p>
+        Host profiles use a different authentication for the URLs matching their URL prefix. The first matching profile is used and the datasource authentication is used when none of the
+        profiles match. Profiles share the network settings of the datasource but never its authentication, headers or secrets. Only the last profile can be removed as the secrets are stored
+        by the position of the profile.
+      </p>
+      {hostProfiles.map((profile, index) => (
+        <HostProfileEditor
+          key={index}
+          {...props}
+          profile={profile}
+          index={index}
+          canRemove={index === hostProfiles.length - 1}
+          onRemove={onRemoveLastProfile}
+        />
+      ))}
+      <Button variant="secondary" size="sm" icon="plus" onClick={onAddProfile}>
+        Add host profile
+      </Button>
+    </>
+  );
+};
@@ -0,0 +1,29 @@
+import React from 'react';
+import { InlineLabel, Input } from '@grafana/ui';
+import type { DataSourcePluginOptionsEditorProps } from '@grafana/data/types';
+import type { InfinityOptions } from './../../types';
+
+export const PaginationLimitsEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const { jsonData } = options;
+  return (
+    <>
+      <h5>Pagination</h5>
+      <p>Limits the number of pages a single query of the datasource can request. The max pages of the queries are capped by this value.</p>
+      <div className="gf-form">
+        <InlineLabel width={20} tooltip="Maximum number of pages per query. Defaults to 5 and can't exceed 1000">
+          Max pages
+        </InlineLabel>
+        <Input
+          type="number"
+          min={1}
+          max={1000}
+          width={20}
+          value={jsonData?.paginationMaxPages}
+          placeholder="5"
+          onChange={(e) => onOptionsChange({ ...options, jsonData: { ...jsonData, paginationMaxPages: e.currentTarget.valueAsNumber || undefined } })}
+        />
+      </div>
+    </>
+  );
+};
@@ -1,20 +1,34 @@
 import React from 'react';
-import { InlineLabel, Input, RadioButtonGroup, useTheme2 } from '@grafana/ui';
+import { InlineLabel, Input, LegacyForms, RadioButtonGroup, useTheme2 } from '@grafana/ui';
+import { SecureTextArea } from './../../components/config/SecureTextArea';
 import type { DataSourcePluginOptionsEditorProps } from '@grafana/data/types';
-import type { InfinityOptions, ProxyType } from './../../types';
+import type { InfinityOptions, InfinitySecureOptions, ProxyType } from './../../types';
 
 type ProxyEditorProps = DataSourcePluginOptionsEditorProps<InfinityOptions>;
 
 export const ProxyEditor = (props: ProxyEditorProps) => {
   const theme = useTheme2();
   const { options, onOptionsChange } = props;
-  const { jsonData } = options;
+  const { jsonData, secureJsonFields } = options;
+  const secureJsonData = (options.secureJsonData || {}) as InfinitySecureOptions;
   const onProxyTypeChange = (proxy_type: ProxyType = 'env') => {
     onOptionsChange({ ...options, jsonData: { ...jsonData, proxy_type } });
   };
   const onProxyUrlChange = (proxy_url: string) => {
     onOptionsChange({ ...options, jsonData: { ...jsonData, proxy_url } });
   };
+  const onProxyUsernameChange = (proxy_username: string) => {
+    onOptionsChange({ ...options, jsonData: { ...jsonData, proxy_username } });
+  };
+  const onNoProxyChange = (no_proxy: string[]) => {
+    onOptionsChange({ ...options, jsonData: { ...jsonData, no_proxy } });
+  };
+  const onSecretChange = (key: keyof Pick<InfinitySecureOptions, 'proxyPassword' | 'proxyCACert'>, value: string) => {
+    onOptionsChange({ ...options, secureJsonData: { ...secureJsonData, [key]: value } });
+  };
+  const onSecretReset = (key: keyof Pick<InfinitySecureOptions, 'proxyPassword' | 'proxyCACert'>) => {
+    onOptionsChange({ ...options, secureJsonFields: { ...secureJsonFields, [key]: false }, secureJsonData: { ...secureJsonData, [key]: '' } });
+  };
   return (
     <>
       <div className="gf-form">
@@ -30,10 +44,44 @@ export const ProxyEditor = (props: ProxyEditorProps) => {
         />
       </div>
       {jsonData?.proxy_type === 'url' && (
-        <div className="gf-form">
-          <InlineLabel width={20}>Proxy URL</InlineLabel>
-          <Input value={jsonData?.proxy_url || ''} onChange={(e) => onProxyUrlChange(e.currentTarget.value)} />
-        </div>
+        <>
+          <div className="gf-form">
+            <InlineLabel width={20} tooltip="Supported schemes are http, https, socks5 and socks5h">
+              Proxy URL
+            </InlineLabel>
+            <Input value={jsonData?.proxy_url || ''} placeholder="http://proxy.example.com:3128" onChange={(e) => onProxyUrlChange(e.currentTarget.value)} />
+          </div>
+          <div className="gf-form">
+            <InlineLabel width={20} tooltip="Optional. Takes precedence over the credentials of the proxy url">
+              Proxy Username
+            </InlineLabel>
+            <Input value={jsonData?.proxy_username || ''} placeholder="(optional) username" onChange={(e) => onProxyUsernameChange(e.currentTarget.value)} />
+          </div>
+          <div className="gf-form">
+            <LegacyForms.SecretFormField
+              labelWidth={10}
+              inputWidth={15}
+              value={secureJsonData.proxyPassword || ''}
+              isConfigured={!!secureJsonFields?.proxyPassword}
+              onReset={() => onSecretReset('proxyPassword')}
+              onChange={(e) => onSecretChange('proxyPassword', e.currentTarget.value)}
+              label="Proxy Password"
+              aria-label="proxy password"
+              placeholder="(optional) password"
+            />
+          </div>
+          {jsonData?.proxy_url?.startsWith('https://') && (
+            <SecureTextArea
+              configured={!!secureJsonFields?.proxyCACert}
+              placeholder="(optional) Begins with -----BEGIN CERTIFICATE-----. Trusted only for the proxy"
+              label="Proxy CA Cert"
+              labelWidth={10}
+              rows={5}
+              onChange={(e) => onSecretChange('proxyCACert', e.currentTarget.value)}
+              onReset={() => onSecretReset('proxyCACert')}
+            />
+          )}
+        </>
       )}
       {(jsonData?.proxy_type === 'env' || !jsonData?.proxy_type) && (
         <>
@@ -45,6 +93,21 @@ export const ProxyEditor = (props: ProxyEditorProps) => {
           </div>
         </>
       )}
+      {jsonData?.proxy_type !== 'none' && (
+        <div className="gf-form">
+          <InlineLabel
+            width={20}
+            tooltip="Hosts connected directly instead of via the proxy. Each entry can be a host name (foo.com), a domain suffix (.foo.com or *.foo.com), a host with port (foo.com:8080), an ip address or a CIDR range (10.0.0.0/8). Use * to bypass the proxy for all the hosts. Enter comma separated values"
+          >
+            No Proxy
+          </InlineLabel>
+          <Input
+            value={(jsonData?.no_proxy || []).join(',')}
+            placeholder="(optional) comma separated hosts"
+            onChange={(e) => onNoProxyChange(e.currentTarget.value ? e.currentTarget.value.split(',') : [])}
+          />
+        </div>
+      )}
     </>
   );
 };
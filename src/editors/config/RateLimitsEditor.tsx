@@ -0,0 +1,63 @@
+import React from 'react';
+import { Button, InlineLabel, Input } from '@grafana/ui';
+import type { DataSourcePluginOptionsEditorProps } from '@grafana/data/types';
+import type { InfinityOptions, RateLimit } from './../../types';
+
+export const RateLimitsEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const rateLimits = options.jsonData?.rateLimits || [];
+  const onRateLimitsChange = (rateLimits: RateLimit[]) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, rateLimits } });
+  };
+  const onRateLimitChange = <T extends keyof RateLimit>(index: number, key: T, value: RateLimit[T]) => {
+    onRateLimitsChange(rateLimits.map((rateLimit, i) => (i === index ? { ...rateLimit, [key]: value } : rateLimit)));
+  };
+  return (
+    <>
+      <h5>Rate limits</h5>
+      <p>
+        Limits the requests to the hosts matching the URL prefix, similar to the allowed hosts. The requests wait for their turn instead of failing. The first matching rate limit is used
+        and the limits are shared by all the queries of the datasource.
+      </p>
+      {rateLimits.map((rateLimit, index) => (
+        <div className="gf-form" key={index}>
+          <InlineLabel width={20} tooltip="URL prefix of the rate limited hosts. ex: https://foo.com">
+            Rate limit {index + 1}
+          </InlineLabel>
+          <Input value={rateLimit.host} width={30} placeholder="https://foo.com" onChange={(e) => onRateLimitChange(index, 'host', e.currentTarget.value)} />
+          <Input
+            type="number"
+            min={0}
+            width={20}
+            value={rateLimit.requestsPerSecond}
+            placeholder="requests per second"
+            title="Requests per second. Empty or zero disables the rate"
+            onChange={(e) => onRateLimitChange(index, 'requestsPerSecond', e.currentTarget.valueAsNumber || undefined)}
+          />
+          <Input
+            type="number"
+            min={0}
+            width={12}
+            value={rateLimit.burst}
+            placeholder="burst (1)"
+            title="Number of requests allowed at once above the rate. Defaults to 1"
+            onChange={(e) => onRateLimitChange(index, 'burst', e.currentTarget.valueAsNumber || undefined)}
+          />
+          <Input
+            type="number"
+            min={0}
+            width={20}
+            value={rateLimit.maxConcurrency}
+            placeholder="max concurrency"
+            title="Maximum number of in-flight requests. Empty or zero disables the concurrency cap"
+            onChange={(e) => onRateLimitChange(index, 'maxConcurrency', e.currentTarget.valueAsNumber || undefined)}
+          />
+          <Button variant="secondary" fill="text" icon="trash-alt" aria-label={`remove rate limit ${index + 1}`} onClick={() => onRateLimitsChange(rateLimits.filter((_, i) => i !== index))} />
+        </div>
+      ))}
+      <Button variant="secondary" size="sm" icon="plus" onClick={() => onRateLimitsChange([...rateLimits, { host: '' }])}>
+        Add rate limit
+      </Button>
+    </>
+  );
+};
@@ -0,0 +1,13 @@
+import React from 'react';
+import { Alert } from '@grafana/ui';
+
+export const SecretReferencesInfo = () => {
+  return (
+    <Alert title="Secret references" severity="info">
+      Instead of the secret itself, any of the secure fields can refer to a file or an environment variable of the grafana server using <code>file:///path/to/secret</code> or{' '}
+      <code>env:VARIABLE_NAME</code>. The references are resolved only when the grafana administrator allows the paths and the environment variable prefixes using the{' '}
+      <code>secret_reference_allowed_paths</code> and <code>secret_reference_allowed_env_prefixes</code> settings of the <code>[plugin.yesoreyeram-infinity-datasource]</code> section of the
+      grafana.ini. The secrets are re-read every <code>secret_reference_refresh_interval</code> (default 5m) and the rotated secrets are used without saving the datasource again.
+    </Alert>
+  );
+};
@@ -1,8 +1,16 @@
-import { InlineFormLabel, LegacyForms, Switch, useTheme } from '@grafana/ui';
+import { InlineFormLabel, Input, LegacyForms, Select, Switch, useTheme } from '@grafana/ui';
 import React from 'react';
 import { SecureTextArea } from './../../components/config/SecureTextArea';
 import type { InfinityOptions, InfinitySecureOptions } from './../../types';
-import type { DataSourcePluginOptionsEditorProps } from '@grafana/data/types';
+import type { DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data/types';
+
+const tlsMinVersions: Array<SelectableValue<string>> = [
+  { value: '', label: 'Default' },
+  { value: '1.0', label: 'TLS 1.0' },
+  { value: '1.1', label: 'TLS 1.1' },
+  { value: '1.2', label: 'TLS 1.2' },
+  { value: '1.3', label: 'TLS 1.3' },
+];
 
 interface TLSConfigEditorProps extends DataSourcePluginOptionsEditorProps<InfinityOptions> {
   hideTile?: boolean;
@@ -23,6 +31,12 @@ export const TLSConfigEditor = (props: TLSConfigEditorProps) => {
       },
     });
   };
+  const onTLSHardeningChange = <T extends keyof Pick<InfinityOptions, 'tlsMinVersion' | 'tlsCipherSuites' | 'tlsPinnedPublicKeys' | 'tlsPinnedHosts'>, V extends InfinityOptions[T]>(
+    key: T,
+    value: V
+  ) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, [key]: value } });
+  };
   const onServerNameChange = (serverName: string) => {
     onOptionsChange({
       ...options,
@@ -122,8 +136,74 @@ export const TLSConfigEditor = (props: TLSConfigEditorProps) => {
               onChange={(e) => onCertificateChange('tlsClientKey', e.currentTarget.value)}
               onReset={() => onCertificateReset('tlsClientKey')}
             />
+            <SecureTextArea
+              configured={!!secureJsonFields?.tlsClientPKCS12}
+              placeholder="(optional) base64 encoded PKCS#12 bundle. Used instead of the client cert and key above"
+              label="PKCS#12 Bundle"
+              labelWidth={10}
+              rows={5}
+              onChange={(e) => onCertificateChange('tlsClientPKCS12', e.currentTarget.value)}
+              onReset={() => onCertificateReset('tlsClientPKCS12')}
+            />
+            <div className="gf-form">
+              <LegacyForms.SecretFormField
+                labelWidth={10}
+                inputWidth={15}
+                value={secureJsonData.tlsClientPKCS12Password || ''}
+                isConfigured={!!secureJsonFields?.tlsClientPKCS12Password}
+                onReset={() => onCertificateReset('tlsClientPKCS12Password')}
+                onChange={(e) => onCertificateChange('tlsClientPKCS12Password', e.currentTarget.value)}
+                label="PKCS#12 Password"
+                aria-label="pkcs12 password"
+                placeholder="(optional) bundle password"
+              />
+            </div>
           </>
         )}
+        <div className="gf-form">
+          <InlineFormLabel width={10} tooltip="Minimum TLS version accepted from the servers. Defaults to TLS 1.2">
+            Min Version
+          </InlineFormLabel>
+          <Select width={30} options={tlsMinVersions} onChange={(v) => onTLSHardeningChange('tlsMinVersion', v.value || '')} value={jsonData.tlsMinVersion || ''} />
+        </div>
+        <div className="gf-form">
+          <InlineFormLabel width={10} tooltip="Cipher suites allowed for TLS 1.0 to 1.2. Insecure cipher suites are rejected. Enter comma separated names such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256">
+            Cipher Suites
+          </InlineFormLabel>
+          <Input
+            width={60}
+            value={(jsonData.tlsCipherSuites || []).join(',')}
+            placeholder="(optional) comma separated cipher suites"
+            onChange={(e) => onTLSHardeningChange('tlsCipherSuites', e.currentTarget.value ? e.currentTarget.value.split(',') : [])}
+          />
+        </div>
+        <div className="gf-form">
+          <InlineFormLabel width={10} tooltip="Base64 encoded sha256 hashes of the public keys (SPKI) accepted from the pinned hosts. Enter comma separated values such as sha256/47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=">
+            Pinned Keys
+          </InlineFormLabel>
+          <Input
+            width={60}
+            value={(jsonData.tlsPinnedPublicKeys || []).join(',')}
+            placeholder="(optional) comma separated sha256/ public key hashes"
+            onChange={(e) => onTLSHardeningChange('tlsPinnedPublicKeys', e.currentTarget.value ? e.currentTarget.value.split(',') : [])}
+          />
+        </div>
+        {(jsonData.tlsPinnedPublicKeys || []).length > 0 && (
+          <div className="gf-form">
+            <InlineFormLabel
+              width={10}
+              tooltip="Host names the pinned keys apply to. Required with the pinned keys. The other hosts such as the oauth token endpoints are not pinned. IP addresses can't be pinned. Enter comma separated values"
+            >
+              Pinned Hosts
+            </InlineFormLabel>
+            <Input
+              width={60}
+              value={(jsonData.tlsPinnedHosts || []).join(',')}
+              placeholder="api.example.com"
+              onChange={(e) => onTLSHardeningChange('tlsPinnedHosts', e.currentTarget.value ? e.currentTarget.value.split(',') : [])}
+            />
+          </div>
+        )}
       </div>
     </>
   );
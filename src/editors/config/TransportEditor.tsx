@@ -0,0 +1,48 @@
+import React from 'react';
+import { InlineLabel, InlineSwitch, Input } from '@grafana/ui';
+import type { DataSourcePluginOptionsEditorProps } from '@grafana/data/types';
+import type { InfinityOptions, TransportProps } from './../../types';
+
+type NumericTransportProp = keyof Omit<TransportProps, 'disableKeepAlives' | 'enableHttp2'>;
+
+const numericTransportProps: Array<{ key: NumericTransportProp; label: string; tooltip: string; defaultValue: number }> = [
+  { key: 'maxIdleConns', label: 'Max idle connections', tooltip: 'Maximum number of idle connections across all the hosts', defaultValue: 100 },
+  { key: 'maxIdleConnsPerHost', label: 'Max idle per host', tooltip: 'Maximum number of idle connections kept per host', defaultValue: 10 },
+  { key: 'idleConnTimeoutInSeconds', label: 'Idle timeout', tooltip: 'Seconds after which the idle connections are closed', defaultValue: 90 },
+  { key: 'dialTimeoutInSeconds', label: 'Dial timeout', tooltip: 'Timeout of establishing the connections in seconds', defaultValue: 30 },
+  { key: 'tlsHandshakeTimeoutInSeconds', label: 'TLS handshake timeout', tooltip: 'Timeout of the tls handshakes in seconds', defaultValue: 10 },
+  { key: 'keepAliveInSeconds', label: 'Keep alive interval', tooltip: 'Interval of the tcp keep-alive probes in seconds', defaultValue: 30 },
+];
+
+export const TransportEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const transport: TransportProps = options.jsonData?.transport || {};
+  const onTransportChange = <T extends keyof TransportProps, V extends TransportProps[T]>(key: T, value: V) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, transport: { ...transport, [key]: value } } });
+  };
+  return (
+    <>
+      <p>The connections are shared by all the queries of the datasource, including the authentication requests. Leave the fields blank to use the defaults.</p>
+      {numericTransportProps.map(({ key, label, tooltip, defaultValue }) => (
+        <div className="gf-form" key={key}>
+          <InlineLabel width={20} tooltip={`${tooltip}. Defaults to ${defaultValue}`}>
+            {label}
+          </InlineLabel>
+          <Input type="number" min={0} width={20} value={transport[key]} placeholder={`${defaultValue}`} onChange={(e) => onTransportChange(key, e.currentTarget.valueAsNumber || undefined)} />
+        </div>
+      ))}
+      <div className="gf-form">
+        <InlineLabel width={20} tooltip="Open a new connection for every request instead of reusing the connections">
+          Disable keep-alives
+        </InlineLabel>
+        <InlineSwitch value={transport.disableKeepAlives || false} onChange={(e) => onTransportChange('disableKeepAlives', e.currentTarget.checked)} />
+      </div>
+      <div className="gf-form">
+        <InlineLabel width={20} tooltip="Use HTTP/2 when supported by the server">
+          Enable HTTP/2
+        </InlineLabel>
+        <InlineSwitch value={transport.enableHttp2 || false} onChange={(e) => onTransportChange('enableHttp2', e.currentTarget.checked)} />
+      </div>
+    </>
+  );
+};
@@ -0,0 +1,160 @@
+import { onUpdateDatasourceSecureJsonDataOption } from '@grafana/data';
+import { InlineFormLabel, InlineSwitch, Input, LegacyForms, RadioButtonGroup, TextArea } from '@grafana/ui';
+import React from 'react';
+import type { InfinityOptions, InfinitySecureOptions, ZCapMode, ZCapProps } from './../../types';
+import type { DataSourcePluginOptionsEditorProps, SelectableValue } from '@grafana/data/types';
+
+const zcapModes: Array<SelectableValue<ZCapMode>> = [
+  { value: 'mercury', label: 'Mercury client' },
+  { value: 'native', label: 'Native' },
+];
+
+export const ZCapInputsEditor = (props: DataSourcePluginOptionsEditorProps<InfinityOptions>) => {
+  const { options, onOptionsChange } = props;
+  const { secureJsonFields } = options;
+  const secureJsonData = (options.secureJsonData || {}) as InfinitySecureOptions;
+  const zcap: ZCapProps = options?.jsonData?.zcap || {};
+  const mode: ZCapMode = zcap.mode || (secureJsonFields?.zcapPrivateKey ? 'native' : 'mercury');
+  const onZCapPropsChange = <T extends keyof ZCapProps, V extends ZCapProps[T]>(key: T, value: V) => {
+    onOptionsChange({ ...options, jsonData: { ...options.jsonData, zcap: { ...zcap, [key]: value } } });
+  };
+  const onResetPrivateKey = () => {
+    onOptionsChange({
+      ...options,
+      secureJsonFields: { ...options.secureJsonFields, zcapPrivateKey: false },
+      secureJsonData: { ...options.secureJsonData, zcapPrivateKey: '' },
+    });
+  };
+  return (
+    <>
+      <div className="gf-form">
+        <InlineFormLabel width={10} tooltip="Native mode signs the capability invocations in the plugin. Mercury client mode uses the external mercury client adapter">
+          Mode
+        </InlineFormLabel>
+        <RadioButtonGroup<ZCapMode> options={zcapModes} onChange={(v) => onZCapPropsChange('mode', v)} value={mode}></RadioButtonGroup>
+      </div>
+      {mode === 'mercury' && (
+        <>
+          <div className="gf-form">
+            <LegacyForms.FormField
+              labelWidth={10}
+              inputWidth={12}
+              value={options.jsonData.zcapJsonPath || ''}
+              onChange={(e) => onOptionsChange({ ...options, jsonData: { ...options.jsonData, zcapJsonPath: e.currentTarget.value } })}
+              label="Resource Target"
+              aria-label="Target URL"
+              placeholder="Target Resource URL"
+              tooltip="Input the url for the resource"
+            />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip="Absolute path of the mercury client adapter binary. Defaults to mercury-client. Custom adapters must be allowed with the zcap_adapter_allowed_paths setting of the [plugin.yesoreyeram-infinity-datasource] section of the grafana config">
+              Adapter Path
+            </InlineFormLabel>
+            <Input onChange={(v) => onZCapPropsChange('adapterPath', v.currentTarget.value)} value={zcap.adapterPath} width={30} placeholder={'mercury-client'} />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip="Arguments passed to the mercury client adapter binary. Enter comma separated values. Require an adapter path allowed by the zcap_adapter_allowed_paths setting of the plugin config">
+              Adapter Args
+            </InlineFormLabel>
+            <Input
+              onChange={(v) => onZCapPropsChange('adapterArgs', v.currentTarget.value ? v.currentTarget.value.split(',') : [])}
+              value={(zcap.adapterArgs || []).join(',')}
+              width={30}
+              placeholder={'(optional) comma separated values of arguments'}
+            />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip="Timeout of a single mercury client adapter operation. Defaults to 60 seconds">
+              Adapter Timeout
+            </InlineFormLabel>
+            <Input
+              type="number"
+              onChange={(v) => onZCapPropsChange('adapterTimeoutInSeconds', v.currentTarget.valueAsNumber || undefined)}
+              value={zcap.adapterTimeoutInSeconds}
+              width={30}
+              placeholder={'60'}
+            />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip="Keep a pool of long-lived mercury client adapter processes instead of starting one per request. The pool uses the same adapter path, which must be allowed by the plugin config when customized">
+              Persistent Adapter
+            </InlineFormLabel>
+            <InlineSwitch value={zcap.persistentAdapter || false} onChange={(e) => onZCapPropsChange('persistentAdapter', e.currentTarget.checked)} />
+          </div>
+          {zcap.persistentAdapter && (
+            <>
+              <div className="gf-form">
+                <InlineFormLabel width={10} tooltip="Number of persistent adapter processes. Defaults to 2">
+                  Pool Size
+                </InlineFormLabel>
+                <Input
+                  type="number"
+                  onChange={(v) => onZCapPropsChange('adapterPoolSize', v.currentTarget.valueAsNumber || undefined)}
+                  value={zcap.adapterPoolSize}
+                  width={30}
+                  placeholder={'2'}
+                />
+              </div>
+              <div className="gf-form">
+                <InlineFormLabel width={10} tooltip="Maximum number of in-flight requests to the persistent adapter processes. Defaults to 8">
+                  Max Concurrency
+                </InlineFormLabel>
+                <Input
+                  type="number"
+                  onChange={(v) => onZCapPropsChange('adapterMaxConcurrency', v.currentTarget.valueAsNumber || undefined)}
+                  value={zcap.adapterMaxConcurrency}
+                  width={30}
+                  placeholder={'8'}
+                />
+              </div>
+            </>
+          )}
+        </>
+      )}
+      {mode === 'native' && (
+        <>
+          <div className="gf-form">
+            <LegacyForms.SecretFormField
+              labelWidth={10}
+              inputWidth={15}
+              required
+              value={secureJsonData.zcapPrivateKey || ''}
+              tooltip="Ed25519 private key. Exported key pair JSON, PEM encoded PKCS#8 key, multibase or base64 encoded key"
+              isConfigured={(secureJsonFields && secureJsonFields.zcapPrivateKey) as boolean}
+              onReset={onResetPrivateKey}
+              onChange={onUpdateDatasourceSecureJsonDataOption(props, 'zcapPrivateKey')}
+              label="Private Key"
+              aria-label="zcap private key"
+              placeholder="Private Key"
+            />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip="Optional. Verification method id of the key. Defaults to the id of the exported key pair">
+              Key ID
+            </InlineFormLabel>
+            <Input onChange={(v) => onZCapPropsChange('keyId', v.currentTarget.value)} value={zcap.keyId} width={30} placeholder={'(optional) did:key:...#...'} />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip="Optional. JSON of the delegated capability. When empty, the root capability of the invocation target is invoked">
+              Capability
+            </InlineFormLabel>
+            <TextArea rows={4} cols={40} onChange={(v) => onZCapPropsChange('capability', v.currentTarget.value)} value={zcap.capability} placeholder={'(optional) delegated capability JSON'} />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip="Optional. Invocation target of the root capability. Defaults to the request URL without query">
+              Invocation Target
+            </InlineFormLabel>
+            <Input onChange={(v) => onZCapPropsChange('invocationTarget', v.currentTarget.value)} value={zcap.invocationTarget} width={30} placeholder={'(optional) invocation target'} />
+          </div>
+          <div className="gf-form">
+            <InlineFormLabel width={10} tooltip="Optional. Defaults to read for GET/HEAD requests and write for the others">
+              Action
+            </InlineFormLabel>
+            <Input onChange={(v) => onZCapPropsChange('action', v.currentTarget.value)} value={zcap.action} width={30} placeholder={'(optional) read'} />
+          </div>
+        </>
+      )}
+    </>
+  );
+};
@@ -13,7 +13,8 @@ import { UQLEditor } from './query.uql';
 import { URLEditor } from './query.url';
 import { ExperimentalFeatures } from './query.experimental';
 import { AzureBlobEditor } from './query.azureBlob';
-import { isDataQuery } from './../../app/utils';
+import { EDVEditor } from './query.edv';
+import { isBackendQuerySupported, isDataQuery, isUQLOrGROQQuery } from './../../app/utils';
 import type { EditorMode, InfinityQuery } from './../../types';
 import { Datasource } from './../../datasource';
 import { PaginationEditor } from './query.pagination';
@@ -75,6 +76,7 @@ export const InfinityQueryEditor = (props: InfinityEditorProps) => {
         {query.type === 'series' && <SeriesEditor {...{ query, onChange }} />}
         {isDataQuery(query) && query.source !== 'inline' && showUrlOptions && <URLEditor {...{ mode, query, onChange, onRunQuery }} />}
         {isDataQuery(query) && query.source === 'azure-blob' && <AzureBlobEditor query={query} onChange={onChange} />}
+        {isDataQuery(query) && query.source === 'edv' && <EDVEditor query={query} onChange={onChange} onRunQuery={onRunQuery} />}
         {canShowColumnsEditor && <QueryColumnsEditor {...{ mode, query, onChange, onRunQuery }} />}
         {canShowFilterEditor && <TableFilter {...{ query, onChange, onRunQuery }} />}
         {query.type === 'uql' && (
@@ -92,7 +94,7 @@ export const InfinityQueryEditor = (props: InfinityEditorProps) => {
         {(query.type === 'json' || query.type === 'graphql' || query.type === 'csv' || query.type === 'tsv' || query.type === 'xml') && query.parser === 'backend' && (
           <ExperimentalFeatures query={query} onChange={onChange} onRunQuery={onRunQuery} />
         )}
-        {query.type === 'json' && query.parser === 'backend' && query.source === 'url' && <PaginationEditor query={query} onChange={onChange} onRunQuery={onRunQuery} />}
+        {((isBackendQuerySupported(query) && query.parser === 'backend') || isUQLOrGROQQuery(query)) && query.source === 'url' && <PaginationEditor query={query} onChange={onChange} onRunQuery={onRunQuery} />}
         {query.type === 'transformations' && <TransformationsEditor query={query} onChange={onChange} onRunQuery={onRunQuery} />}
       </EditorRows>
     </div>
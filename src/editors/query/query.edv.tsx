@@ -0,0 +1,102 @@
+import React, { useState } from 'react';
+import { Input } from '@grafana/ui';
+import { Stack } from '../../components/extended/Stack';
+import { EditorRow } from '../../components/extended/EditorRow';
+import { EditorField } from '../../components/extended/EditorField';
+import { isDataQuery } from './../../app/utils';
+import type { InfinityEDVOptions, InfinityQuery } from './../../types';
+
+type EDVEditorProps = { query: InfinityQuery; onChange: (query: InfinityQuery) => void; onRunQuery: () => void };
+
+const splitValues = (value: string): string[] =>
+  value
+    .split(',')
+    .map((v) => v.trim())
+    .filter(Boolean);
+
+const equalsToString = (equals: InfinityEDVOptions['equals'] = []): string =>
+  equals
+    .flatMap((e) => Object.entries(e || {}))
+    .map(([k, v]) => `${k}=${v}`)
+    .join(',');
+
+const stringToEquals = (value: string): InfinityEDVOptions['equals'] => {
+  const entries = splitValues(value)
+    .map((v) => v.split('='))
+    .filter((kv) => kv.length === 2 && kv[0].trim() !== '')
+    .map(([k, v]) => [k.trim(), v.trim()]);
+  return entries.length > 0 ? [Object.fromEntries(entries)] : [];
+};
+
+export const EDVEditor = (props: EDVEditorProps) => {
+  const { query, onChange, onRunQuery } = props;
+  const edvQuery = isDataQuery(query) && query.source === 'edv' ? query : undefined;
+  const [url, setURL] = useState(edvQuery?.url || '');
+  const [documentIds, setDocumentIds] = useState((edvQuery?.edv_options?.document_ids || []).join(','));
+  const [index, setIndex] = useState(edvQuery?.edv_options?.index || '');
+  const [equals, setEquals] = useState(equalsToString(edvQuery?.edv_options?.equals));
+  const [has, setHas] = useState((edvQuery?.edv_options?.has || []).join(','));
+  if (!edvQuery) {
+    return <></>;
+  }
+  const onEDVOptionsChange = <K extends keyof InfinityEDVOptions, V extends InfinityEDVOptions[K]>(key: K, value: V) => {
+    onChange({ ...edvQuery, edv_options: { ...edvQuery.edv_options, [key]: value } });
+    onRunQuery();
+  };
+  return (
+    <EditorRow label="EDV details" collapsible={false} collapsed={true} title={() => ''}>
+      <Stack gap={1} direction="row" wrap={true}>
+        <EditorField label="Vault URL" horizontal={true} tooltip="URL of the encrypted data vault. Requires zcap authentication using the mercury client adapter">
+          <Input
+            value={url}
+            width={84}
+            placeholder="https://example.com/edvs/z123"
+            onChange={(e) => setURL(e.currentTarget.value)}
+            onBlur={() => {
+              onChange({ ...edvQuery, url });
+              onRunQuery();
+            }}
+          />
+        </EditorField>
+        <EditorField label="Document IDs" horizontal={true} tooltip="Comma separated ids of the documents to fetch. When empty, the documents matching the index filters are listed">
+          <Input
+            value={documentIds}
+            width={49}
+            placeholder="(optional) comma separated document ids"
+            onChange={(e) => setDocumentIds(e.currentTarget.value)}
+            onBlur={() => onEDVOptionsChange('document_ids', splitValues(documentIds))}
+          />
+        </EditorField>
+        {documentIds.trim() === '' && (
+          <>
+            <EditorField label="Index" horizontal={true}>
+              <Input value={index} width={39} placeholder="urn:example:index" onChange={(e) => setIndex(e.currentTarget.value)} onBlur={() => onEDVOptionsChange('index', index)} />
+            </EditorField>
+            <EditorField label="Equals" horizontal={true} tooltip="Comma separated attribute=value pairs. Documents matching all the pairs are listed">
+              <Input
+                value={equals}
+                width={39}
+                placeholder="(optional) name=value"
+                onChange={(e) => setEquals(e.currentTarget.value)}
+                onBlur={() => onEDVOptionsChange('equals', stringToEquals(equals))}
+              />
+            </EditorField>
+            <EditorField label="Has" horizontal={true} tooltip="Comma separated attribute names the documents must have">
+              <Input value={has} width={39} placeholder="(optional) name" onChange={(e) => setHas(e.currentTarget.value)} onBlur={() => onEDVOptionsChange('has', splitValues(has))} />
+            </EditorField>
+            <EditorField label="Limit" horizontal={true}>
+              <Input
+                type="number"
+                value={edvQuery.edv_options?.limit}
+                width={16}
+                placeholder="(optional)"
+                onChange={(e) => onChange({ ...edvQuery, edv_options: { ...edvQuery.edv_options, limit: e.currentTarget.valueAsNumber || undefined } })}
+                onBlur={onRunQuery}
+              />
+            </EditorField>
+          </>
+        )}
+      </Stack>
+    </EditorRow>
+  );
+};
@@ -1,10 +1,10 @@
 import React from 'react';
-import { InlineLabel, Input, Select } from '@grafana/ui';
+import { InlineLabel, InlineSwitch, Input, Select } from '@grafana/ui';
 import { SelectableValue } from '@grafana/data';
 import { EditorField } from './../../components/extended/EditorField';
 import { EditorRow } from './../../components/extended/EditorRow';
 import { Stack } from './../../components/extended/Stack';
-import type { InfinityQuery, PaginationParamType, PaginationType } from './../../types';
+import type { InfinityQuery, PaginationCursorSource, PaginationParamType, PaginationTimeWindowFormat, PaginationType } from './../../types';
 
 const paginationTypes: Array<SelectableValue<PaginationType>> = [
   { value: 'none', label: 'None' },
@@ -12,16 +12,30 @@ const paginationTypes: Array<SelectableValue<PaginationType>> = [
   { value: 'page', label: 'Page number' },
   { value: 'cursor', label: 'Cursor' },
   { value: 'list', label: 'List of values' },
+  { value: 'next_link', label: 'Next link' },
+  { value: 'relay', label: 'GraphQL Relay' },
+  { value: 'time_window', label: 'Time window' },
 ];
 
 const paginationParamTypes: Array<SelectableValue<PaginationParamType>> = [
   { value: 'query', label: 'Query param' },
   { value: 'header', label: 'Header' },
   { value: 'body_data', label: 'Body form' },
-  // { value: 'body_json', label: 'Body JSON' },
+  { value: 'body_json', label: 'Body JSON' },
   { value: 'replace', label: 'Replace URL' },
 ];
 
+const cursorSources: Array<SelectableValue<PaginationCursorSource>> = [
+  { value: 'body', label: 'Response body' },
+  { value: 'header', label: 'Response header' },
+];
+
+const timeWindowFormats: Array<SelectableValue<PaginationTimeWindowFormat>> = [
+  { value: 'rfc3339', label: 'RFC3339' },
+  { value: 'unix', label: 'Epoch (seconds)' },
+  { value: 'unix_ms', label: 'Epoch (milliseconds)' },
+];
+
 type PaginationEditorProps = {
   query: InfinityQuery;
   onChange: (query: InfinityQuery) => void;
@@ -38,18 +52,57 @@ export const PaginationEditor = (props: PaginationEditorProps) => {
             <Select<PaginationType> width={30} value={query.pagination_mode || 'none'} options={paginationTypes} onChange={(e) => onChange({ ...query, pagination_mode: e.value || 'none' })} />
           </EditorField>
           {query.pagination_mode && query.pagination_mode !== 'none' && (
-            <EditorField label="Max pages" tooltip={'maximum of 5 pages. minimum of 1 page. Default 1'}>
+            <EditorField
+              label="Max pages"
+              tooltip={
+                query.pagination_mode === 'time_window'
+                  ? 'maximum number of the time windows. Defaults to and limited by the pagination max pages of the datasource which defaults to 5 pages'
+                  : 'minimum of 1 page. Default 1. Limited by the pagination max pages of the datasource which defaults to 5 pages'
+              }
+            >
               <Input
                 type={'number'}
                 min={1}
-                max={5}
                 width={30}
                 value={query.pagination_max_pages}
-                onChange={(e) => onChange({ ...query, pagination_max_pages: e.currentTarget.valueAsNumber || 1 })}
-                placeholder="min:1, max:5"
+                onChange={(e) => onChange({ ...query, pagination_max_pages: e.currentTarget.valueAsNumber || (query.pagination_mode === 'time_window' ? undefined : 1) })}
+                placeholder={query.pagination_mode === 'time_window' ? 'default: datasource max pages' : 'min:1, default max:5'}
+              />
+            </EditorField>
+          )}
+          {(query.pagination_mode === 'offset' || query.pagination_mode === 'page' || query.pagination_mode === 'list' || query.pagination_mode === 'time_window') && (
+            <EditorField label="Parallel requests" tooltip={'number of the pages fetched concurrently. maximum of 10. Default 1'}>
+              <Input
+                type={'number'}
+                min={1}
+                max={10}
+                width={30}
+                value={query.pagination_parallelism}
+                onChange={(e) => onChange({ ...query, pagination_parallelism: e.currentTarget.valueAsNumber || 1 })}
+                placeholder="min:1, max:10"
               />
             </EditorField>
           )}
+          {query.pagination_mode && query.pagination_mode !== 'none' && (
+            <EditorField label="Max rows" tooltip={'stop requesting the next pages once the total rows limit is reached. Leave empty for no limit'}>
+              <Input
+                type={'number'}
+                min={0}
+                width={30}
+                value={query.pagination_max_rows}
+                onChange={(e) => onChange({ ...query, pagination_max_rows: e.currentTarget.valueAsNumber || 0 })}
+                placeholder="no limit"
+              />
+            </EditorField>
+          )}
+          {query.pagination_mode && query.pagination_mode !== 'none' && (
+            <EditorField
+              label="Partial results"
+              tooltip={'return the successful pages with a warning notice per failed page instead of failing the query. Keep it disabled for the alerting queries'}
+            >
+              <InlineSwitch value={query.pagination_partial_results || false} onChange={(e) => onChange({ ...query, pagination_partial_results: e.currentTarget.checked })} />
+            </EditorField>
+          )}
         </Stack>
         {(query.pagination_mode === 'offset' || query.pagination_mode === 'page' || query.pagination_mode === 'cursor') && (
           <>
@@ -142,6 +195,51 @@ export const PaginationEditor = (props: PaginationEditorProps) => {
                   </Stack>
                 </EditorField>
               )}
+              {(query.pagination_mode === 'offset' || query.pagination_mode === 'page') && (
+                <EditorField label="Stop conditions">
+                  <Stack>
+                    <InlineLabel width={24} tooltip="stop when the page has no rows">
+                      Stop on empty page
+                    </InlineLabel>
+                    <InlineSwitch
+                      value={query.pagination_stop_on_empty_page || false}
+                      onChange={(e) => onChange({ ...query, pagination_stop_on_empty_page: e.currentTarget.checked })}
+                    />
+                    <InlineLabel width={24} tooltip="stop when the page has fewer rows than the page size">
+                      Stop on partial page
+                    </InlineLabel>
+                    <InlineSwitch
+                      value={query.pagination_stop_on_partial_page || false}
+                      onChange={(e) => onChange({ ...query, pagination_stop_on_partial_page: e.currentTarget.checked })}
+                    />
+                    <InlineLabel width={24} tooltip="stop when the response is identical to the previous page">
+                      Stop on duplicate page
+                    </InlineLabel>
+                    <InlineSwitch
+                      value={query.pagination_stop_on_duplicate_page || false}
+                      onChange={(e) => onChange({ ...query, pagination_stop_on_duplicate_page: e.currentTarget.checked })}
+                    />
+                    <InlineLabel width={20} tooltip="selector of the has more field of the response. pagination stops when the value is false or missing. column name of the trailing row for csv">
+                      Has more path
+                    </InlineLabel>
+                    <Input
+                      width={20}
+                      value={query.pagination_param_has_more_extraction_path || ''}
+                      onChange={(e) => onChange({ ...query, pagination_param_has_more_extraction_path: e.currentTarget.value })}
+                      placeholder="has_more"
+                    />
+                    <InlineLabel width={20} tooltip="selector of the total items field of the response. pagination stops once the total items are fetched. column name of the trailing row for csv">
+                      Total path
+                    </InlineLabel>
+                    <Input
+                      width={20}
+                      value={query.pagination_param_total_extraction_path || ''}
+                      onChange={(e) => onChange({ ...query, pagination_param_total_extraction_path: e.currentTarget.value })}
+                      placeholder="total"
+                    />
+                  </Stack>
+                </EditorField>
+              )}
               {query.pagination_mode === 'cursor' && (
                 <EditorField label="Cursor field">
                   <Stack>
@@ -159,18 +257,49 @@ export const PaginationEditor = (props: PaginationEditorProps) => {
                       value={query.pagination_param_cursor_field_type || 'query'}
                       onChange={(e) => onChange({ ...query, pagination_param_cursor_field_type: e.value || 'query' })}
                     />
-                    <InlineLabel width={20} tooltip="selector to extract the cursor">
+                    <InlineLabel width={20} tooltip="extract the cursor from the response body or from the response header such as x-ms-continuation">
+                      Extract from
+                    </InlineLabel>
+                    <Select<PaginationCursorSource>
+                      width={20}
+                      options={cursorSources}
+                      value={query.pagination_param_cursor_extraction_source || 'body'}
+                      onChange={(e) => onChange({ ...query, pagination_param_cursor_extraction_source: e.value || 'body' })}
+                    />
+                    <InlineLabel
+                      width={20}
+                      tooltip={
+                        query.pagination_param_cursor_extraction_source === 'header'
+                          ? 'name of the response header of the cursor'
+                          : 'selector to extract the cursor. JSON selector for json and graphql, selector or XPath for xml and the column name of the trailing row for csv'
+                      }
+                    >
                       Extraction path
                     </InlineLabel>
                     <Input
                       width={20}
                       value={query.pagination_param_cursor_extraction_path}
                       onChange={(e) => onChange({ ...query, pagination_param_cursor_extraction_path: e.currentTarget.value || '' })}
-                      placeholder="selector to extract the cursor"
+                      placeholder={query.pagination_param_cursor_extraction_source === 'header' ? 'X-Next-Token' : 'selector to extract the cursor'}
                     ></Input>
                   </Stack>
                 </EditorField>
               )}
+              {query.pagination_mode === 'cursor' && (
+                <EditorField label="Stop conditions" tooltip={'pagination always stops on an empty or repeated cursor'}>
+                  <Stack>
+                    <InlineLabel width={20} tooltip="selector of the has more field of the response. pagination stops when the value is false or missing. column name of the trailing row for csv">
+                      Has more path
+                    </InlineLabel>
+                    <Input
+                      width={20}
+                      value={query.pagination_param_has_more_extraction_path || ''}
+                      onChange={(e) => onChange({ ...query, pagination_param_has_more_extraction_path: e.currentTarget.value })}
+                      placeholder="has_more"
+                    />
+                  </Stack>
+                </EditorField>
+              )}
             </Stack>
           </>
         )}
@@ -207,6 +336,111 @@ export const PaginationEditor = (props: PaginationEditorProps) => {
             </Stack>
           </>
         )}
+        {query.pagination_mode === 'relay' && (
+          <Stack gap={1} wrap={false} direction="column">
+            <EditorField label="Relay connection" tooltip={'Root selector is the path of the relay connection, for example data.repository.issues. Nodes of the connection edges are used as rows'}>
+              <Stack>
+                <InlineLabel width={20} tooltip="graphql variable of the cursor">
+                  Cursor variable
+                </InlineLabel>
+                <Input
+                  width={20}
+                  value={query.pagination_param_cursor_field_name || ''}
+                  onChange={(e) => onChange({ ...query, pagination_param_cursor_field_name: e.currentTarget.value })}
+                  placeholder="after"
+                />
+                <InlineLabel width={20} tooltip="selector of the end cursor. defaults to the pageInfo.endCursor of the connection">
+                  End cursor path
+                </InlineLabel>
+                <Input
+                  width={30}
+                  value={query.pagination_param_cursor_extraction_path || ''}
+                  onChange={(e) => onChange({ ...query, pagination_param_cursor_extraction_path: e.currentTarget.value })}
+                  placeholder="<root>.pageInfo.endCursor"
+                />
+                <InlineLabel width={20} tooltip="selector of the has next page flag. defaults to the pageInfo.hasNextPage of the connection">
+                  Has next page path
+                </InlineLabel>
+                <Input
+                  width={30}
+                  value={query.pagination_param_has_more_extraction_path || ''}
+                  onChange={(e) => onChange({ ...query, pagination_param_has_more_extraction_path: e.currentTarget.value })}
+                  placeholder="<root>.pageInfo.hasNextPage"
+                />
+              </Stack>
+            </EditorField>
+          </Stack>
+        )}
+        {query.pagination_mode === 'time_window' && (
+          <Stack gap={1} wrap={false} direction="column">
+            <EditorField label="Time window" tooltip={'dashboard time range is split into the windows of the duration. up to the pagination max pages of the datasource, or the max pages of the query when set'}>
+              <Stack>
+                <InlineLabel width={12}>Duration</InlineLabel>
+                <Input
+                  width={20}
+                  value={query.pagination_time_window_duration || ''}
+                  onChange={(e) => onChange({ ...query, pagination_time_window_duration: e.currentTarget.value })}
+                  placeholder="1h/1d. defaults to 1d"
+                />
+                <InlineLabel width={12}>Format</InlineLabel>
+                <Select<PaginationTimeWindowFormat>
+                  width={30}
+                  options={timeWindowFormats}
+                  value={query.pagination_time_window_format || 'rfc3339'}
+                  onChange={(e) => onChange({ ...query, pagination_time_window_format: e.value || 'rfc3339' })}
+                />
+              </Stack>
+            </EditorField>
+            <EditorField label="Start field">
+              <Stack>
+                <InlineLabel width={12}>Field name</InlineLabel>
+                <Input
+                  width={30}
+                  value={query.pagination_param_start_field_name || ''}
+                  onChange={(e) => onChange({ ...query, pagination_param_start_field_name: e.currentTarget.value })}
+                  placeholder="from"
+                />
+                <InlineLabel width={12}>Field type</InlineLabel>
+                <Select<PaginationParamType>
+                  width={20}
+                  options={paginationParamTypes}
+                  value={query.pagination_param_start_field_type || 'query'}
+                  onChange={(e) => onChange({ ...query, pagination_param_start_field_type: e.value || 'query' })}
+                />
+              </Stack>
+            </EditorField>
+            <EditorField label="End field">
+              <Stack>
+                <InlineLabel width={12}>Field name</InlineLabel>
+                <Input
+                  width={30}
+                  value={query.pagination_param_end_field_name || ''}
+                  onChange={(e) => onChange({ ...query, pagination_param_end_field_name: e.currentTarget.value })}
+                  placeholder="to"
+                />
+                <InlineLabel width={12}>Field type</InlineLabel>
+                <Select<PaginationParamType>
+                  width={20}
+                  options={paginationParamTypes}
+                  value={query.pagination_param_end_field_type || 'query'}
+                  onChange={(e) => onChange({ ...query, pagination_param_end_field_type: e.value || 'query' })}
+                />
+              </Stack>
+            </EditorField>
+          </Stack>
+        )}
+        {query.pagination_mode === 'next_link' && (
+          <Stack gap={1} wrap={false} direction="column">
+            <EditorField label="Next link" tooltip={'Selector to extract the next page url from the response. When empty, the url with rel="next" of the Link response header is used. Column name of the trailing row for csv'}>
+              <Input
+                width={40}
+                value={query.pagination_param_next_link_extraction_path || ''}
+                onChange={(e) => onChange({ ...query, pagination_param_next_link_extraction_path: e.currentTarget.value })}
+                placeholder="defaults to the Link header"
+              />
+            </EditorField>
+          </Stack>
+        )}
       </Stack>
     </EditorRow>
   );
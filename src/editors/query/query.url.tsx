@@ -26,7 +26,7 @@ export const Method = ({ query, onChange, onRunQuery }: { query: InfinityQuery;
   if (!(isDataQuery(query) || query.type === 'uql' || query.type === 'groq')) {
     return <></>;
   }
-  if (query.source === 'inline' || query.source === 'azure-blob') {
+  if (query.source === 'inline' || query.source === 'azure-blob' || query.source === 'edv') {
     return <></>;
   }
   const URL_METHODS: SelectableValue[] = [
@@ -94,7 +94,7 @@ const Headers = ({ query, onChange }: { query: InfinityQuery; onChange: (value:
   if (!(isDataQuery(query) || query.type === 'uql' || query.type === 'groq')) {
     return <></>;
   }
-  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob') {
+  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob' || query.source === 'edv') {
     return <></>;
   }
   const defaultHeader = {
@@ -117,7 +117,7 @@ const QueryParams = ({ query, onChange, onRunQuery }: { query: InfinityQuery; on
   if (!(isDataQuery(query) || query.type === 'uql' || query.type === 'groq')) {
     return <></>;
   }
-  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob') {
+  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob' || query.source === 'edv') {
     return <></>;
   }
   const defaultParam = {
@@ -140,7 +140,7 @@ const Body = ({ query, onChange, onRunQuery }: { query: InfinityQuery; onChange:
   if (!(isDataQuery(query) || query.type === 'uql' || query.type === 'groq')) {
     return <></>;
   }
-  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob') {
+  if (query.source === 'inline' || query.source === 'reference' || query.source === 'azure-blob' || query.source === 'edv') {
     return <></>;
   }
   const placeholderGraphQLQuery = `{ query : { }}`;
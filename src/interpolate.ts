@@ -46,6 +46,9 @@ export const interpolateQuery = (query: InfinityQuery, scopedVars: ScopedVars):
     if (newQuery.source === 'inline') {
       newQuery.data = replaceVariable(newQuery.data, scopedVars);
     }
+    if (newQuery.source === 'edv') {
+      newQuery.url = replaceVariable(newQuery.url || '', scopedVars);
+    }
     if (isDataQuery(newQuery)) {
       newQuery.filters = (newQuery.filters || []).map((filter) => {
         const value = (filter.value || []).map((val) => {
@@ -7,7 +7,7 @@ export interface GlobalInfinityQuery {
   id: string;
   query: InfinityQuery;
 }
-export type AuthType = 'none' | 'basicAuth' | 'apiKey' | 'bearerToken' | 'oauthPassThru' | 'digestAuth' | 'aws' | 'azureBlob' | 'oauth2' | 'zcap';
+export type AuthType = 'none' | 'basicAuth' | 'apiKey' | 'bearerToken' | 'oauthPassThru' | 'digestAuth' | 'aws' | 'azureBlob' | 'oauth2' | 'zcap' | 'azureAD' | 'hmac';
 export type OAuth2Type = 'client_credentials' | 'jwt' | 'others';
 export type APIKeyType = 'header' | 'query';
 export type OAuth2Props = {
@@ -25,23 +25,97 @@ export type AWSAuthProps = {
   region?: string;
   service?: string;
 };
+export type AzureADAuthType = 'clientSecret' | 'clientCertificate';
+export type AzureADCloud = 'AzurePublic' | 'AzureChina' | 'AzureUSGovernment';
+export type AzureADProps = {
+  authType?: AzureADAuthType;
+  cloud?: AzureADCloud;
+  authorityHost?: string;
+  tenantId?: string;
+  clientId?: string;
+  scopes?: string[];
+};
+export type HMACProps = {
+  stringToSign?: string;
+  algorithm?: 'sha1' | 'sha256' | 'sha512';
+  encoding?: 'hex' | 'base64';
+  secretEncoding?: '' | 'base64';
+  timestampFormat?: 'unix' | 'unix_ms' | 'rfc3339' | 'rfc1123';
+  timestampHeader?: string;
+  signatureHeader?: string;
+  signaturePrefix?: string;
+};
+export type ZCapMode = 'mercury' | 'native';
+export type ZCapProps = {
+  mode?: ZCapMode;
+  keyId?: string;
+  capability?: string;
+  invocationTarget?: string;
+  action?: string;
+  adapterPath?: string;
+  adapterArgs?: string[];
+  adapterTimeoutInSeconds?: number;
+  persistentAdapter?: boolean;
+  adapterPoolSize?: number;
+  adapterMaxConcurrency?: number;
+};
+export type HostProfile = {
+  name?: string;
+  urlPrefix?: string;
+  auth_method?: AuthType;
+  basicAuth?: boolean;
+  basicAuthUser?: string;
+  apiKeyKey?: string;
+  apiKeyType?: APIKeyType;
+};
 export type InfinityReferenceData = { name: string; data: string };
 export type ProxyType = 'none' | 'env' | 'url';
+export type DNSOverride = { host: string; port?: string; ip: string };
+export type RateLimit = { host: string; requestsPerSecond?: number; burst?: number; maxConcurrency?: number };
+export type CircuitBreakerProps = { enabled?: boolean; failureThreshold?: number; openDurationInSeconds?: number };
+export type TransportProps = {
+  maxIdleConns?: number;
+  maxIdleConnsPerHost?: number;
+  idleConnTimeoutInSeconds?: number;
+  dialTimeoutInSeconds?: number;
+  tlsHandshakeTimeoutInSeconds?: number;
+  keepAliveInSeconds?: number;
+  disableKeepAlives?: boolean;
+  enableHttp2?: boolean;
+};
 export interface InfinityOptions extends DataSourceJsonData {
   auth_method?: AuthType;
   apiKeyKey?: string;
   apiKeyType?: APIKeyType;
   oauth2?: OAuth2Props;
   aws?: AWSAuthProps;
+  azureAD?: AzureADProps;
+  hmac?: HMACProps;
+  zcap?: ZCapProps;
+  hostProfiles?: HostProfile[];
   zcapJsonPath?: string; 
   tlsSkipVerify?: boolean;
   tlsAuth?: boolean;
   serverName?: string;
   tlsAuthWithCACert?: boolean;
+  tlsMinVersion?: string;
+  tlsCipherSuites?: string[];
+  tlsPinnedPublicKeys?: string[];
+  tlsPinnedHosts?: string[];
   global_queries?: GlobalInfinityQuery[];
   timeoutInSeconds?: number;
   proxy_type?: ProxyType;
   proxy_url?: string;
+  proxy_username?: string;
+  no_proxy?: string[];
+  dnsOverrides?: DNSOverride[];
+  dnsServer?: string;
+  transport?: TransportProps;
+  rateLimits?: RateLimit[];
+  paginationMaxPages?: number;
+  circuitBreaker?: CircuitBreakerProps;
+  serveStaleOnError?: boolean;
+  staleMaxAgeInSeconds?: number;
   oauthPassThru?: boolean;
   allowedHosts?: string[];
   refData?: InfinityReferenceData[];
@@ -60,6 +134,10 @@ export interface InfinitySecureOptions {
   tlsCACert?: string;
   tlsClientCert?: string;
   tlsClientKey?: string;
+  tlsClientPKCS12?: string;
+  tlsClientPKCS12Password?: string;
+  proxyPassword?: string;
+  proxyCACert?: string;
   apiKeyValue?: string;
   bearerToken?: string;
   awsAccessKey?: string;
@@ -67,6 +145,11 @@ export interface InfinitySecureOptions {
   oauth2ClientSecret?: string;
   oauth2JWTPrivateKey?: string;
   azureBlobAccountKey?: string;
+  azureADClientSecret?: string;
+  azureADClientCertificate?: string;
+  azureADClientCertificatePassword?: string;
+  hmacSecret?: string;
+  zcapPrivateKey?: string;
 }
 export interface SecureField {
   id: string;
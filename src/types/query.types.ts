@@ -3,7 +3,7 @@ import type { DataQuery, SelectableValue } from '@grafana/data';
 
 //#region Query
 export type InfinityQueryType = 'json' | 'csv' | 'tsv' | 'xml' | 'graphql' | 'html' | 'series' | 'global' | 'uql' | 'groq' | 'google-sheets' | 'transformations';
-export type InfinityQuerySources = 'url' | 'inline' | 'azure-blob' | 'reference' | 'random-walk' | 'expression';
+export type InfinityQuerySources = 'url' | 'inline' | 'azure-blob' | 'edv' | 'reference' | 'random-walk' | 'expression';
 export type InfinityColumnFormat = 'string' | 'number' | 'timestamp' | 'timestamp_epoch' | 'timestamp_epoch_s' | 'boolean';
 export type InfinityQueryFormat = 'table' | 'timeseries' | 'logs' | 'trace' | 'node-graph-nodes' | 'node-graph-edges' | 'dataframe' | 'as-is';
 export type QueryBodyType = 'none' | 'form-data' | 'x-www-form-urlencoded' | 'raw' | 'graphql';
@@ -36,6 +36,18 @@ export type InfinityQueryWithAzureBlobSource<T extends InfinityQueryType> = {
   azBlobName: string;
 } & InfinityQueryWithSource<'azure-blob'> &
   InfinityQueryBase<T>;
+export type InfinityEDVOptions = {
+  document_ids?: string[];
+  index?: string;
+  equals?: Array<Record<string, string>>;
+  has?: string[];
+  limit?: number;
+};
+export type InfinityQueryWithEDVSource<T extends InfinityQueryType> = {
+  url: string;
+  edv_options?: InfinityEDVOptions;
+} & InfinityQueryWithSource<'edv'> &
+  InfinityQueryBase<T>;
 export type InfinityQueryWithInlineSource<T extends InfinityQueryType> = {
   data: string;
 } & InfinityQueryWithSource<'inline'> &
@@ -47,7 +59,7 @@ export type InfinityQueryWithDataSource<T extends InfinityQueryType> = {
   columns: InfinityColumn[];
   filters?: InfinityFilter[];
   format: InfinityQueryFormat;
-} & (InfinityQueryWithURLSource<T> | InfinityQueryWithInlineSource<T> | InfinityQueryWithReferenceSource<T> | InfinityQueryWithAzureBlobSource<T>) &
+} & (InfinityQueryWithURLSource<T> | InfinityQueryWithInlineSource<T> | InfinityQueryWithReferenceSource<T> | InfinityQueryWithAzureBlobSource<T> | InfinityQueryWithEDVSource<T>) &
   InfinityQueryBase<T>;
 export type InfinityJSONQueryOptions = {
   root_is_not_array?: boolean;
@@ -104,9 +116,18 @@ export type InfinityUQLQuery = { uql: string; format: InfinityQueryFormat } & In
 export type InfinityGROQQuerySource = InfinityQueryWithURLSource<'groq'> | InfinityQueryWithInlineSource<'groq'>;
 export type InfinityGROQQuery = { groq: string; format: InfinityQueryFormat } & InfinityGROQQuerySource & InfinityQueryBase<'groq'>;
 export type InfinityGSheetsQuery = { spreadsheet: string; sheetName?: string; range: string; columns: InfinityColumn[] } & InfinityQueryBase<'google-sheets'>;
-export type PaginationType = 'none' | 'offset' | 'page' | 'cursor' | 'list';
+export type PaginationType = 'none' | 'offset' | 'page' | 'cursor' | 'list' | 'next_link' | 'relay' | 'time_window';
 export type PaginationParamType = 'query' | 'header' | 'body_data' | 'body_json' | 'replace';
-export type PaginationBase<T extends PaginationType> = { pagination_mode?: T; pagination_max_pages?: number };
+export type PaginationCursorSource = 'body' | 'header';
+export type PaginationBase<T extends PaginationType> = { pagination_mode?: T; pagination_max_pages?: number; pagination_max_rows?: number; pagination_partial_results?: boolean };
+export type PaginationParallelism = { pagination_parallelism?: number };
+export type PaginationStopConditions = {
+  pagination_stop_on_empty_page?: boolean;
+  pagination_stop_on_partial_page?: boolean;
+  pagination_stop_on_duplicate_page?: boolean;
+  pagination_param_has_more_extraction_path?: string;
+  pagination_param_total_extraction_path?: string;
+};
 export type PaginationNone = {} & PaginationBase<'none'>;
 export type PaginationOffset = {
   pagination_param_size_field_name?: string;
@@ -115,7 +136,9 @@ export type PaginationOffset = {
   pagination_param_offset_field_name?: string;
   pagination_param_offset_field_type?: PaginationParamType;
   pagination_param_offset_value?: number;
-} & PaginationBase<'offset'>;
+} & PaginationStopConditions &
+  PaginationParallelism &
+  PaginationBase<'offset'>;
 export type PaginationPage = {
   pagination_param_size_field_name?: string;
   pagination_param_size_field_type?: PaginationParamType;
@@ -123,7 +146,9 @@ export type PaginationPage = {
   pagination_param_page_field_name?: string;
   pagination_param_page_field_type?: PaginationParamType;
   pagination_param_page_value?: number;
-} & PaginationBase<'page'>;
+} & PaginationStopConditions &
+  PaginationParallelism &
+  PaginationBase<'page'>;
 export type PaginationCursor = {
   pagination_param_size_field_name?: string;
   pagination_param_size_field_type?: PaginationParamType;
@@ -131,13 +156,34 @@ export type PaginationCursor = {
   pagination_param_cursor_field_name?: string;
   pagination_param_cursor_field_type?: PaginationParamType;
   pagination_param_cursor_extraction_path?: string;
+  pagination_param_cursor_extraction_source?: PaginationCursorSource;
+  pagination_param_has_more_extraction_path?: string;
 } & PaginationBase<'cursor'>;
 export type PaginationList = {
   pagination_param_list_field_name?: string;
   pagination_param_list_field_type?: PaginationParamType;
   pagination_param_list_value?: string;
-} & PaginationBase<'list'>;
-export type Pagination = PaginationNone | PaginationOffset | PaginationPage | PaginationCursor | PaginationList;
+} & PaginationParallelism &
+  PaginationBase<'list'>;
+export type PaginationNextLink = {
+  pagination_param_next_link_extraction_path?: string;
+} & PaginationBase<'next_link'>;
+export type PaginationRelay = {
+  pagination_param_cursor_field_name?: string;
+  pagination_param_cursor_extraction_path?: string;
+  pagination_param_has_more_extraction_path?: string;
+} & PaginationBase<'relay'>;
+export type PaginationTimeWindowFormat = 'rfc3339' | 'unix' | 'unix_ms';
+export type PaginationTimeWindow = {
+  pagination_time_window_duration?: string;
+  pagination_time_window_format?: PaginationTimeWindowFormat;
+  pagination_param_start_field_name?: string;
+  pagination_param_start_field_type?: PaginationParamType;
+  pagination_param_end_field_name?: string;
+  pagination_param_end_field_type?: PaginationParamType;
+} & PaginationParallelism &
+  PaginationBase<'time_window'>;
+export type Pagination = PaginationNone | PaginationOffset | PaginationPage | PaginationCursor | PaginationList | PaginationNextLink | PaginationRelay | PaginationTimeWindow;
 export type Transformation = 'limit' | 'filterExpression' | 'summarize' | 'computedColumn';
 export type TransformationItem = {
   type: Transformation;